@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/dtrejod/goexif/internal/ilog"
+	"github.com/dtrejod/goexif/internal/mediadate"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+const (
+	clockShowsFlagName = "shows"
+)
+
+var (
+	clockShows string
+)
+
+var clockSkewCmd = &cobra.Command{
+	Use:   "clock-skew",
+	Short: "Print the clock skew rule for the camera that took a reference photo of a clock",
+	Run:   clockSkewRun,
+}
+
+func clockSkewRun(_ *cobra.Command, _ []string) {
+	rule, err := mediadate.ClockSkewRule(ctx, sourceFile, magicSignatureIn, clockShows)
+	if err != nil {
+		ilog.FromContext(ctx).Error("Failed to derive clock skew rule from mediafile.",
+			zap.String("sourceFile", sourceFile),
+			zap.Error(err))
+		os.Exit(1)
+	}
+
+	out, err := json.Marshal(rule)
+	if err != nil {
+		ilog.FromContext(ctx).Error("Failed to encode clock skew rule.", zap.Error(err))
+		os.Exit(1)
+	}
+	fmt.Println(string(out))
+}
+
+func init() {
+	clockSkewCmd.Flags().StringVar(&sourceFile,
+		sourceFileFlagName,
+		"",
+		"Reference photo of a clock")
+	clockSkewCmd.Flags().StringVar(&clockShows,
+		clockShowsFlagName,
+		"",
+		"Time the clock in the photo shows (HH:MM[:SS] or YYYY-MM-DD HH:MM[:SS])")
+	clockSkewCmd.Flags().BoolVar(&magicSignatureIn,
+		magicSignatureInFlagName,
+		false,
+		"Ignore existing file extension and use magic signature instead when identifying files")
+
+	_ = clockSkewCmd.MarkFlagRequired(sourceFileFlagName)
+	_ = clockSkewCmd.MarkFlagRequired(clockShowsFlagName)
+	rootCmd.AddCommand(clockSkewCmd)
+}
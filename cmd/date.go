@@ -2,7 +2,10 @@ package cmd
 
 import (
 	"os"
+	"strings"
 
+	"github.com/dtrejod/goexif/internal/clockskew"
+	"github.com/dtrejod/goexif/internal/dateresolver"
 	"github.com/dtrejod/goexif/internal/ilog"
 	"github.com/dtrejod/goexif/internal/mediadate"
 	"github.com/spf13/cobra"
@@ -11,6 +14,12 @@ import (
 
 var (
 	sourceFileFlagName = "src-file"
+
+	dateSourcesFlagUsage = "Ordered list of date sources to try. The first source with a date wins. " +
+		"Overrides gps-time, fallback-filename and fallback-mod-time. The " + dateresolver.Container + " alias names the " +
+		"video and audio container sources not listed elsewhere. Known sources: " +
+		strings.Join(dateresolver.KnownSources, ",")
+	clockSkewRulesFlagUsage = "JSON rule file of camera Make/Model/BodySerialNumber clock offsets applied to camera dates"
 )
 
 var (
@@ -24,7 +33,25 @@ var dateCmd = &cobra.Command{
 }
 
 func dateRun(_ *cobra.Command, _ []string) {
-	if err := mediadate.Print(ctx, sourceFile, magicSignatureIn); err != nil {
+	names := dateSources
+	if len(names) == 0 {
+		names = dateresolver.Defaults(gpsTime, false, false)
+	}
+	resolver, err := dateresolver.NewFromNames(names, nil)
+	if err != nil {
+		ilog.FromContext(ctx).Error("Invalid date sources.", zap.Error(err))
+		os.Exit(1)
+	}
+	if clockSkewRules != "" {
+		rules, err := clockskew.Load(clockSkewRules)
+		if err != nil {
+			ilog.FromContext(ctx).Error("Invalid clock skew rules.", zap.Error(err))
+			os.Exit(1)
+		}
+		resolver.SetClockSkew(rules)
+	}
+
+	if err := mediadate.Print(ctx, sourceFile, magicSignatureIn, resolver); err != nil {
 		ilog.FromContext(ctx).Error("Failed to print date for mediafile.",
 			zap.String("sourceFile", sourceFile),
 			zap.Error(err))
@@ -41,6 +68,18 @@ func init() {
 		magicSignatureInFlagName,
 		false,
 		"Ignore existing file extension and use magic signature instead when identifying files")
+	dateCmd.Flags().BoolVar(&gpsTime,
+		gpsTimeFlagName,
+		false,
+		"Prefer GPS time over the camera capture time and infer the local timezone from the GPS coordinates")
+	dateCmd.Flags().StringSliceVar(&dateSources,
+		dateSourcesFlagName,
+		nil,
+		dateSourcesFlagUsage)
+	dateCmd.Flags().StringVar(&clockSkewRules,
+		clockSkewRulesFlagName,
+		"",
+		clockSkewRulesFlagUsage)
 
 	_ = dateCmd.MarkFlagRequired(sourceFileFlagName)
 	rootCmd.AddCommand(dateCmd)
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/dtrejod/goexif/internal/mediatype"
+	"github.com/spf13/cobra"
+)
+
+var formatsCmd = &cobra.Command{
+	Use:   "formats",
+	Short: "Print the known media formats and how they are identified, dated and compared for duplicates",
+	Run:   formatsRun,
+}
+
+func formatsRun(_ *cobra.Command, _ []string) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FORMAT\tEXTENSIONS\tSIGNATURE\tDATE SOURCES\tDUPLICATES")
+	for _, r := range mediatype.Registrations() {
+		// the format name leads its aliases
+		var aliases []string
+		for alias := range r.Media.Aliases() {
+			if alias != r.Media.String() {
+				aliases = append(aliases, alias)
+			}
+		}
+		slices.Sort(aliases)
+		aliases = append([]string{r.Media.String()}, aliases...)
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			r.Media,
+			strings.Join(aliases, ","),
+			orNone(r.Signature()),
+			orNone(strings.Join(nativeDateSources(r), ",")),
+			orNone(r.Duplicates.Name))
+	}
+	_ = w.Flush()
+}
+
+// nativeDateSources returns the names of the date sources reading the native date metadata of the format
+func nativeDateSources(r mediatype.Registration) []string {
+	if r.Dates == nil {
+		return nil
+	}
+	return r.Dates.Sources()
+}
+
+// orNone returns a dash for an empty column
+func orNone(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func init() {
+	rootCmd.AddCommand(formatsCmd)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/dtrejod/goexif/internal/ilog"
+	"github.com/dtrejod/goexif/internal/mediaformats"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -16,10 +17,18 @@ var (
 	rootCmd     = &cobra.Command{
 		Use:               "goexif",
 		Short:             "A tool for interacting with media files via their exif/file metadata",
-		PersistentPreRunE: initLoggers,
+		PersistentPreRunE: initRoot,
 	}
 )
 
+// initRoot initializes the loggers and the known media formats before running any command
+func initRoot(cmd *cobra.Command, args []string) error {
+	if err := initLoggers(cmd, args); err != nil {
+		return err
+	}
+	return mediaformats.Register()
+}
+
 func initLoggers(_ *cobra.Command, _ []string) error {
 	ctx = context.Background()
 	var err error
@@ -3,9 +3,13 @@ package cmd
 import (
 	"os"
 	"regexp"
+	"time"
 
+	"github.com/dtrejod/goexif/internal/dateresolver"
+	"github.com/dtrejod/goexif/internal/ilog"
 	"github.com/dtrejod/goexif/internal/mediasort"
 	"github.com/spf13/cobra"
+	"go.uber.org/zap"
 )
 
 const (
@@ -21,6 +25,20 @@ const (
 	magicSignatureOutFlagName = "magic-ext-out"
 	fileTypesFlagName         = "file-types"
 	blocklistRegexFlagName    = "blocklist-re"
+	gpsTimeFlagName           = "gps-time"
+	filenameFallbackFlagName  = "fallback-filename"
+	filenamePatternFlagName   = "filename-re"
+	dateSourcesFlagName       = "date-sources"
+	minDateFlagName           = "min-date"
+	maxDateFlagName           = "max-date"
+	suspiciousDatesFlagName   = "suspicious-dates"
+	clockSkewRulesFlagName    = "clock-skew-rules"
+	livePhotosFlagName        = "live-photos"
+	companionsFlagName        = "companions"
+	companionExtFlagName      = "companion-ext"
+
+	// flagDateLayout is the layout of date flags. The time may be omitted.
+	flagDateLayout = "2006-01-02 15:04:05"
 )
 
 var (
@@ -36,6 +54,17 @@ var (
 	stopOnError       bool
 	fileTypes         []string
 	blocklistRe       []string
+	gpsTime           bool
+	filenameFallback  bool
+	filenamePatterns  []string
+	dateSources       []string
+	minDate           string
+	maxDate           string
+	suspiciousDates   []string
+	clockSkewRules    string
+	livePhotos        bool
+	companions        bool
+	companionExts     []string
 )
 
 var sortCmd = &cobra.Command{
@@ -58,6 +87,30 @@ func sortRun(_ *cobra.Command, _ []string) {
 	if modTimeFallback {
 		opts = append(opts, mediasort.WithLastModifiedFallback())
 	}
+	if gpsTime {
+		opts = append(opts, mediasort.WithGPSTime())
+	}
+	if filenameFallback || len(filenamePatterns) > 0 {
+		opts = append(opts, mediasort.WithFilenameFallback(filenamePatterns))
+	}
+	if len(dateSources) > 0 {
+		opts = append(opts, mediasort.WithDateSources(dateSources))
+	}
+	if clockSkewRules != "" {
+		opts = append(opts, mediasort.WithClockSkewRules(clockSkewRules))
+	}
+	if livePhotos {
+		opts = append(opts, mediasort.WithLivePhotos())
+	}
+	if companions {
+		opts = append(opts, mediasort.WithCompanions(companionExts))
+	}
+	dateOpts, err := dateValidationOptions()
+	if err != nil {
+		ilog.FromContext(ctx).Error("Invalid date flags.", zap.Error(err))
+		os.Exit(1)
+	}
+	opts = append(opts, dateOpts...)
 	if magicSignatureIn {
 		opts = append(opts, mediasort.WithInputFileMagicSignature())
 	}
@@ -107,6 +160,51 @@ func init() {
 		modTimeFallbackFlagName,
 		false,
 		"Fallback to using file modified time if no exif data is found")
+	sortCmd.Flags().BoolVar(&gpsTime,
+		gpsTimeFlagName,
+		false,
+		"Prefer GPS time over the camera capture time and infer the local timezone from the GPS coordinates")
+	sortCmd.Flags().BoolVar(&filenameFallback,
+		filenameFallbackFlagName,
+		false,
+		"Fallback to a date found in the filename (e.g. IMG_20200626_231926.jpg) if no metadata is found")
+	sortCmd.Flags().StringArrayVar(&filenamePatterns,
+		filenamePatternFlagName,
+		nil,
+		"Additional filename regex with named groups year, month, day and optionally hour, minute, second, ms, ampm. Implies fallback-filename")
+	sortCmd.Flags().StringSliceVar(&dateSources,
+		dateSourcesFlagName,
+		nil,
+		dateSourcesFlagUsage)
+	sortCmd.Flags().StringVar(&minDate,
+		minDateFlagName,
+		dateresolver.DefaultMinDate.Format(time.DateOnly),
+		"Treat media dated before this date (YYYY-MM-DD) as having no date. Empty disables the bound")
+	sortCmd.Flags().StringVar(&maxDate,
+		maxDateFlagName,
+		"",
+		"Treat media dated after this date (YYYY-MM-DD) as having no date. Defaults to a day from now")
+	sortCmd.Flags().StringSliceVar(&suspiciousDates,
+		suspiciousDatesFlagName,
+		sliceTimeToString(dateresolver.DefaultSuspiciousDates),
+		"Default dates of devices with a reset clock and zero timestamp dates (YYYY-MM-DD[ HH:MM:SS]) that are treated "+
+			"as no date")
+	sortCmd.Flags().StringVar(&clockSkewRules,
+		clockSkewRulesFlagName,
+		"",
+		clockSkewRulesFlagUsage)
+	sortCmd.Flags().BoolVar(&livePhotos,
+		livePhotosFlagName,
+		false,
+		"Keep Apple Live Photo videos together with their image by moving them to the image's destination and name")
+	sortCmd.Flags().BoolVar(&companions,
+		companionsFlagName,
+		false,
+		"Move files sharing a base name (e.g. IMG_0001.JPG, IMG_0001.CR2, IMG_0001.xmp) together as one unit")
+	sortCmd.Flags().StringSliceVar(&companionExts,
+		companionExtFlagName,
+		mediasort.DefaultCompanionExtensions,
+		"Extensions of non-media files that move together with media sharing their base name. Used with companions")
 	sortCmd.Flags().BoolVar(&detectDuplicates,
 		detectDuplicatesFlagName,
 		false,
@@ -126,8 +224,9 @@ func init() {
 	sortCmd.Flags().BoolVar(&stopOnError, stopOnErrorFlagName, false, "Exit on first error")
 	sortCmd.Flags().StringArrayVar(&fileTypes,
 		fileTypesFlagName,
-		mediasort.DefaultFileTypes,
-		"Allowlist of file types to match on. NOTE: When used in conjuction with mag-ext-in, then magic metadata may be used")
+		nil,
+		"Allowlist of file types to match on. Defaults to all file types listed by the formats command. NOTE: When "+
+			"used in conjuction with mag-ext-in, then magic metadata may be used")
 	sortCmd.Flags().StringArrayVar(&blocklistRe,
 		blocklistRegexFlagName,
 		sliceReToString(mediasort.DefaultBlocklist),
@@ -137,6 +236,56 @@ func init() {
 	rootCmd.AddCommand(sortCmd)
 }
 
+// dateValidationOptions returns the sorter options for the date validation flags
+func dateValidationOptions() ([]mediasort.Option, error) {
+	var opts []mediasort.Option
+	if minDate == "" {
+		opts = append(opts, mediasort.WithMinDate(time.Time{}))
+	} else {
+		t, err := parseFlagDate(minDate)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, mediasort.WithMinDate(t))
+	}
+	if maxDate != "" {
+		t, err := parseFlagDate(maxDate)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, mediasort.WithMaxDate(t))
+	}
+
+	dates := make([]time.Time, 0, len(suspiciousDates))
+	for _, d := range suspiciousDates {
+		// gracefully handle the no dates case
+		if d == "" {
+			continue
+		}
+		t, err := parseFlagDate(d)
+		if err != nil {
+			return nil, err
+		}
+		dates = append(dates, t)
+	}
+	return append(opts, mediasort.WithSuspiciousDates(dates)), nil
+}
+
+func parseFlagDate(s string) (time.Time, error) {
+	if t, err := time.Parse(flagDateLayout, s); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, s)
+}
+
+func sliceTimeToString(in []time.Time) []string {
+	out := make([]string, 0, len(in))
+	for _, t := range in {
+		out = append(out, t.Format(flagDateLayout))
+	}
+	return out
+}
+
 func sliceReToString(in []*regexp.Regexp) []string {
 	out := make([]string, 0, len(in))
 	for _, r := range in {
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/dtrejod/goexif/internal/ilog"
+	"github.com/dtrejod/goexif/internal/mediaverify"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+const (
+	fixFlagName    = "fix"
+	outputFlagName = "output"
+
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+var (
+	verifyDir string
+	fix       bool
+	output    string
+)
+
+var verifyTypesCmd = &cobra.Command{
+	Use:   "verify-types",
+	Short: "Report media files whose extension does not match their magic signature",
+	Run:   verifyTypesRun,
+}
+
+func verifyTypesRun(_ *cobra.Command, _ []string) {
+	if output != outputTable && output != outputJSON {
+		ilog.FromContext(ctx).Error("Invalid output format.", zap.String("output", output))
+		os.Exit(1)
+	}
+
+	mismatches, err := mediaverify.Verify(ctx, verifyDir, fix)
+	var fixErr *mediaverify.FixError
+	if err != nil && !errors.As(err, &fixErr) {
+		ilog.FromContext(ctx).Error("Failed to verify media types.",
+			zap.String("sourceDir", verifyDir),
+			zap.Error(err))
+		os.Exit(1)
+	}
+
+	if output == outputJSON {
+		if mismatches == nil {
+			mismatches = []mediaverify.Mismatch{}
+		}
+		out, err := json.Marshal(mismatches)
+		if err != nil {
+			ilog.FromContext(ctx).Error("Failed to encode mismatches.", zap.Error(err))
+			os.Exit(1)
+		}
+		fmt.Println(string(out))
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "PATH\tEXTENSION\tDETECTED\tCONFIDENCE\tRENAMED")
+		for _, m := range mismatches {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", m.Path, m.ExtType, m.DetectedType, m.Confidence, orNone(m.FixedPath))
+		}
+		_ = w.Flush()
+	}
+
+	// the mismatches are reported before failing, so the files left to rename are known
+	if fixErr != nil {
+		ilog.FromContext(ctx).Error("Failed to rename mismatched files.",
+			zap.Strings("paths", fixErr.Paths),
+			zap.Error(fixErr.Err))
+		os.Exit(1)
+	}
+}
+
+func init() {
+	verifyTypesCmd.Flags().StringVarP(&verifyDir, sourceDirFlagName, "s", "", "Source directory to scan for media files")
+	verifyTypesCmd.Flags().BoolVar(&fix,
+		fixFlagName,
+		false,
+		"Rename mismatched files in place to the extension of their detected media type")
+	verifyTypesCmd.Flags().StringVar(&output,
+		outputFlagName,
+		outputTable,
+		"Output format of the mismatches (table or json)")
+
+	_ = verifyTypesCmd.MarkFlagRequired(sourceDirFlagName)
+	rootCmd.AddCommand(verifyTypesCmd)
+}
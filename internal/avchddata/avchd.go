@@ -0,0 +1,182 @@
+package avchddata
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"time"
+)
+
+const (
+	// tsPacketSize is the size of MPEG-TS packets. M2TS packets, used by AVCHD, prefix them with a 4 byte timestamp.
+	// Ref: https://en.wikipedia.org/wiki/MPEG_transport_stream
+	tsPacketSize   = 188
+	m2tsPacketSize = tsPacketSize + 4
+	syncByte       = 0x47
+	// minSyncPackets is the number of packets checked for the sync byte when identifying a transport stream
+	minSyncPackets = 4
+	// HeaderSize is the number of bytes needed by IsTransportStream
+	HeaderSize = m2tsPacketSize * minSyncPackets
+
+	// maxScanLength bounds how far into the stream the recording date is looked for. Camcorders write it in the first
+	// access unit of every group of pictures.
+	maxScanLength = 16 << 20
+	// maxUnitLength bounds the size of the start of a PES packet that is searched for the recording date
+	maxUnitLength = 16 << 10
+)
+
+var (
+	// seiUUID is the UUID of the H.264 user data unregistered SEI message holding the MDPM data
+	seiUUID = []byte{0x17, 0xee, 0x8c, 0x60, 0xf8, 0x4d, 0x11, 0xd9, 0x8c, 0xd6, 0x08, 0x00, 0x20, 0x0c, 0x9a, 0x66}
+	// seiMarker is found in the video stream right before the MDPM entries
+	seiMarker = append(append([]byte{}, seiUUID...), mdpmMarker...)
+)
+
+// IsTransportStream returns true if the provided header is the start of an MPEG-TS or M2TS stream. At least HeaderSize
+// bytes are needed.
+func IsTransportStream(header []byte) bool {
+	_, ok := packetLayout(header)
+	return ok
+}
+
+// packetLayout returns the packet size of the transport stream. The sync byte is found at the start of TS packets and
+// after the timestamp of M2TS packets.
+func packetLayout(header []byte) (int, bool) {
+	for _, size := range []int{tsPacketSize, m2tsPacketSize} {
+		offset := size - tsPacketSize
+		if len(header) < offset+size*(minSyncPackets-1)+1 {
+			continue
+		}
+		ok := true
+		for i := 0; i < minSyncPackets; i++ {
+			if header[offset+i*size] != syncByte {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return size, true
+		}
+	}
+	return 0, false
+}
+
+// GetTime returns the recording date from the AVCHD media referenced in the provided path. The date is read from the
+// MDPM data of the H.264 stream. When the stream has no date, then the clip info and playlist files of the AVCHD
+// directory structure are tried. If the camera recorded its timezone, then the returned time is in that offset.
+// Otherwise the time is in exifdata.UnknownLocation.
+func GetTime(path string) (time.Time, error) {
+	t, err := getTimeFromStream(path)
+	if err == nil {
+		return t, nil
+	}
+	if t, playlistErr := getTimeFromPlaylists(path); playlistErr == nil {
+		return t, nil
+	}
+	return time.Time{}, err
+}
+
+func getTimeFromStream(path string) (time.Time, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(io.LimitReader(f, maxScanLength))
+	header, err := r.Peek(HeaderSize)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return time.Time{}, err
+	}
+	size, ok := packetLayout(header)
+	if !ok {
+		return time.Time{}, errors.New("not an MPEG transport stream")
+	}
+
+	// units holds the start of the current PES packet of each PID
+	units := make(map[uint16][]byte)
+	packet := make([]byte, size)
+	for {
+		if _, err := io.ReadFull(r, packet); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				break
+			}
+			return time.Time{}, err
+		}
+
+		pid, start, payload, err := parsePacket(packet[size-tsPacketSize:])
+		if err != nil {
+			return time.Time{}, err
+		}
+		if start {
+			if t, err := getTimeFromUnit(units[pid]); err == nil {
+				return t, nil
+			}
+			units[pid] = append(units[pid][:0], payload...)
+		} else if unit, ok := units[pid]; ok && len(unit) < maxUnitLength {
+			units[pid] = append(unit, payload...)
+		}
+	}
+
+	for _, unit := range units {
+		if t, err := getTimeFromUnit(unit); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errMDPMNotFound
+}
+
+// parsePacket returns the PID, the payload unit start indicator and the payload of a TS packet
+func parsePacket(p []byte) (uint16, bool, []byte, error) {
+	if p[0] != syncByte {
+		return 0, false, nil, errors.New("lost MPEG transport stream sync")
+	}
+	pid := uint16(p[1]&0x1f)<<8 | uint16(p[2])
+	start := p[1]&0x40 != 0
+
+	control := p[3] >> 4 & 0x03
+	payload := p[4:]
+	if control&0x02 != 0 {
+		// skip the adaptation field
+		n := int(payload[0]) + 1
+		if n > len(payload) {
+			return 0, false, nil, errors.New("invalid MPEG transport stream adaptation field")
+		}
+		payload = payload[n:]
+	}
+	if control&0x01 == 0 {
+		payload = nil
+	}
+	return pid, start, payload, nil
+}
+
+// getTimeFromUnit returns the recording date from the SEI message found in the start of a PES packet
+func getTimeFromUnit(unit []byte) (time.Time, error) {
+	i := bytes.Index(unit, seiMarker)
+	if i < 0 {
+		return time.Time{}, errMDPMNotFound
+	}
+	return getTimeFromMDPM(unescapeRBSP(unit[i+len(seiUUID):]))
+}
+
+// unescapeRBSP removes the emulation prevention bytes that H.264 inserts after two zero bytes
+// Ref: https://en.wikipedia.org/wiki/Network_Abstraction_Layer
+func unescapeRBSP(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	zeros := 0
+	for _, b := range data {
+		if zeros >= 2 && b == 0x03 {
+			zeros = 0
+			continue
+		}
+		if b == 0 {
+			zeros++
+		} else {
+			zeros = 0
+		}
+		out = append(out, b)
+	}
+	return out
+}
@@ -0,0 +1,179 @@
+package avchddata
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dtrejod/goexif/internal/exifdata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mdpm returns MDPM entries with the provided timezone and BCD encoded recording date
+func mdpm(tz byte, date ...byte) []byte {
+	return append([]byte("MDPM\x03"),
+		0x18, tz, date[0], date[1], date[2],
+		0xe0, 0x01, 0x08, 0x00, 0x00,
+		0x19, date[3], date[4], date[5], date[6],
+	)
+}
+
+// sei returns a PES packet holding an access unit delimiter and an SEI message with the provided MDPM entries. The
+// entries are escaped like the H.264 stream. The filler pushes the SEI message across TS packets.
+func sei(entries []byte) []byte {
+	pes := []byte{0x00, 0x00, 0x01, 0xe0, 0x00, 0x00, 0x80, 0x00, 0x00}
+	pes = append(pes, 0x00, 0x00, 0x00, 0x01, 0x09, 0xf0)
+	pes = append(pes, 0x00, 0x00, 0x00, 0x01, 0x0c)
+	pes = append(pes, make([]byte, 170)...)
+	pes = append(pes, 0xff, 0x00, 0x00, 0x00, 0x01, 0x06, 0x05, byte(len(seiUUID)+len(entries)))
+	pes = append(pes, seiUUID...)
+	var zeros int
+	for _, b := range entries {
+		if zeros >= 2 && b <= 0x03 {
+			pes = append(pes, 0x03)
+			zeros = 0
+		}
+		if b == 0 {
+			zeros++
+		} else {
+			zeros = 0
+		}
+		pes = append(pes, b)
+	}
+	return append(pes, 0x80)
+}
+
+// stream returns the PES packet split into TS packets of the provided size
+func stream(size int, pes []byte) []byte {
+	var out []byte
+	start := true
+	for len(pes) > 0 {
+		n := min(len(pes), tsPacketSize-4)
+		out = append(out, make([]byte, size-tsPacketSize)...)
+		header := []byte{syncByte, 0x10, 0x11, 0x10}
+		if start {
+			header[1] |= 0x40
+		}
+		out = append(out, header...)
+		if n < tsPacketSize-4 {
+			// stuff the adaptation field to fill the packet
+			out[len(out)-1] |= 0x20
+			stuffing := tsPacketSize - 4 - n - 1
+			out = append(out, byte(stuffing))
+			if stuffing > 0 {
+				out = append(out, 0x00)
+				for i := 1; i < stuffing; i++ {
+					out = append(out, 0xff)
+				}
+			}
+		}
+		out = append(out, pes[:n]...)
+		pes = pes[n:]
+		start = false
+	}
+	// pad the stream with null packets
+	for i := 0; i < minSyncPackets; i++ {
+		out = append(out, make([]byte, size-tsPacketSize)...)
+		out = append(out, syncByte, 0x1f, 0xff, 0x10)
+		out = append(out, make([]byte, tsPacketSize-4)...)
+	}
+	return out
+}
+
+func writeFile(t *testing.T, path string, data []byte) string {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, data, 0644))
+	return path
+}
+
+func TestGetTime(t *testing.T) {
+	// 2023-10-01 00:00:02 is escaped in the stream
+	date := []byte{0x20, 0x23, 0x10, 0x01, 0x00, 0x00, 0x02}
+
+	t.Run("m2ts with timezone", func(t *testing.T) {
+		path := writeFile(t, filepath.Join(t.TempDir(), "00000.MTS"), stream(m2tsPacketSize, sei(mdpm(0x12, date...))))
+		actual, err := GetTime(path)
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2023, 10, 1, 0, 0, 2, 0, time.FixedZone("", 9*3600)), actual)
+	})
+
+	t.Run("negative half hour timezone", func(t *testing.T) {
+		path := writeFile(t, filepath.Join(t.TempDir(), "00000.MTS"), stream(m2tsPacketSize, sei(mdpm(0x27, date...))))
+		actual, err := GetTime(path)
+		require.NoError(t, err)
+		_, offset := actual.Zone()
+		assert.Equal(t, -(3*3600 + 1800), offset)
+	})
+
+	t.Run("ts without timezone", func(t *testing.T) {
+		path := writeFile(t, filepath.Join(t.TempDir(), "video.m2t"), stream(tsPacketSize, sei(mdpm(0xff, date...))))
+		actual, err := GetTime(path)
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2023, 10, 1, 0, 0, 2, 0, exifdata.UnknownLocation), actual)
+	})
+
+	t.Run("playlist fallback", func(t *testing.T) {
+		bdmv := filepath.Join(t.TempDir(), "PRIVATE", "AVCHD", "BDMV")
+		path := writeFile(t, filepath.Join(bdmv, "STREAM", "00001.MTS"), stream(m2tsPacketSize, sei(nil)))
+		writeFile(t, filepath.Join(bdmv, "PLAYLIST", "00000.MPL"),
+			append([]byte("MPLS0100\x0000000M2TS"), mdpm(0xff, 0x20, 0x19, 0x01, 0x01, 0x00, 0x00, 0x00)...))
+		writeFile(t, filepath.Join(bdmv, "PLAYLIST", "00001.MPL"),
+			append([]byte("MPLS0100\x0000001M2TS"), mdpm(0xff, date...)...))
+
+		actual, err := GetTime(path)
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2023, 10, 1, 0, 0, 2, 0, exifdata.UnknownLocation), actual)
+	})
+
+	t.Run("clip info fallback", func(t *testing.T) {
+		bdmv := filepath.Join(t.TempDir(), "BDMV")
+		path := writeFile(t, filepath.Join(bdmv, "STREAM", "00001.MTS"), stream(m2tsPacketSize, sei(nil)))
+		writeFile(t, filepath.Join(bdmv, "CLIPINF", "00001.CPI"), append([]byte("HDMV0100"), mdpm(0xff, date...)...))
+
+		actual, err := GetTime(path)
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2023, 10, 1, 0, 0, 2, 0, exifdata.UnknownLocation), actual)
+	})
+
+	t.Run("without date", func(t *testing.T) {
+		_, err := GetTime(writeFile(t, filepath.Join(t.TempDir(), "00000.MTS"), stream(m2tsPacketSize, sei(nil))))
+		assert.Error(t, err)
+	})
+
+	for name, date := range map[string][]byte{
+		"invalid month":  {0x20, 0x23, 0x13, 0x01, 0x00, 0x00, 0x00},
+		"invalid hour":   {0x20, 0x23, 0x10, 0x01, 0x24, 0x00, 0x00},
+		"invalid minute": {0x20, 0x23, 0x10, 0x01, 0x00, 0x60, 0x00},
+		"invalid second": {0x20, 0x23, 0x10, 0x01, 0x00, 0x00, 0x60},
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := writeFile(t, filepath.Join(t.TempDir(), "00000.MTS"), stream(m2tsPacketSize, sei(mdpm(0xff, date...))))
+			_, err := GetTime(path)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestIsTransportStream(t *testing.T) {
+	assert.True(t, IsTransportStream(stream(m2tsPacketSize, sei(nil))))
+	assert.True(t, IsTransportStream(stream(tsPacketSize, sei(nil))))
+
+	// header returns the magic number padded to a full header
+	header := func(magic string) []byte {
+		return append([]byte(magic), make([]byte, HeaderSize-len(magic))...)
+	}
+	for name, data := range map[string][]byte{
+		"gif":     []byte("GIF89a"),
+		"zeros":   make([]byte, HeaderSize),
+		"jpeg":    header("\xff\xd8\xff\xe0\x00\x10JFIF\x00"),
+		"png":     header("\x89PNG\r\n\x1a\n"),
+		"mp4":     header("\x00\x00\x00\x18ftypmp42\x00\x00\x00\x00mp42isom"),
+		"avi":     header("RIFF\x00\x00\x00\x00AVI LIST"),
+		"mpeg ps": header("\x00\x00\x01\xba\x44\x00\x04\x00\x04\x01"),
+	} {
+		assert.False(t, IsTransportStream(data), name)
+	}
+}
@@ -0,0 +1,100 @@
+package avchddata
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dtrejod/goexif/internal/exifdata"
+)
+
+const (
+	// mdpmEntrySize is the size of an MDPM entry, a tag followed by 4 bytes of data
+	mdpmEntrySize = 5
+	// dateTag holds the timezone, year and month, and timeTag the day, hour, minute and second of the recording date
+	dateTag = 0x18
+	timeTag = 0x19
+	// timezoneUnset is set in the timezone byte by cameras that do not record a timezone
+	timezoneUnset = 0x80
+)
+
+var (
+	// mdpmMarker starts the makers private data of the recording. In the video stream it follows the UUID of an H.264
+	// user data unregistered SEI message.
+	// Ref: https://exiftool.org/TagNames/H264.html
+	mdpmMarker = []byte("MDPM")
+
+	errMDPMNotFound = errors.New("could not find MDPM recording date")
+)
+
+// getTimeFromMDPM returns the recording date from the MDPM entries that follow the MDPM marker in data. The entries are
+// a count followed by 5 byte entries.
+func getTimeFromMDPM(data []byte) (time.Time, error) {
+	i := bytes.Index(data, mdpmMarker)
+	if i < 0 {
+		return time.Time{}, errMDPMNotFound
+	}
+	data = data[i+len(mdpmMarker):]
+	if len(data) < 1 {
+		return time.Time{}, errMDPMNotFound
+	}
+
+	count := int(data[0])
+	entries := data[1:]
+	var date, clock []byte
+	for i := 0; i < count && (i+1)*mdpmEntrySize <= len(entries); i++ {
+		entry := entries[i*mdpmEntrySize : (i+1)*mdpmEntrySize]
+		switch entry[0] {
+		case dateTag:
+			date = entry[1:]
+		case timeTag:
+			clock = entry[1:]
+		}
+	}
+	if date == nil || clock == nil {
+		return time.Time{}, errMDPMNotFound
+	}
+	return parseMDPMDate(date, clock)
+}
+
+// parseMDPMDate parses the BCD encoded recording date. The first date byte is the timezone: bit 7 is set when there is
+// no timezone, bit 5 is the sign, bits 1-4 the hours and bit 0 adds 30 minutes. Bit 6 flags daylight saving time, which
+// is already part of the offset. Dates without a timezone are in exifdata.UnknownLocation.
+func parseMDPMDate(date, clock []byte) (time.Time, error) {
+	values := make([]int, 0, 7)
+	for _, b := range append(append([]byte{}, date[1:]...), clock...) {
+		v, ok := bcd(b)
+		if !ok {
+			return time.Time{}, fmt.Errorf("invalid MDPM date: % x % x", date, clock)
+		}
+		values = append(values, v)
+	}
+	year := values[0]*100 + values[1]
+	t := time.Date(year, time.Month(values[2]), values[3], values[4], values[5], values[6], 0, exifdata.UnknownLocation)
+	// time.Date normalizes out of range values, so a changed field means the date is invalid
+	if t.Month() != time.Month(values[2]) || t.Day() != values[3] || t.Hour() != values[4] || t.Minute() != values[5] ||
+		t.Second() != values[6] {
+		return time.Time{}, fmt.Errorf("invalid MDPM date: % x % x", date, clock)
+	}
+
+	tz := date[0]
+	if tz&timezoneUnset != 0 {
+		return t, nil
+	}
+	offset := int(tz>>1&0x0f)*3600 + int(tz&0x01)*1800
+	if tz&0x20 != 0 {
+		offset = -offset
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0,
+		time.FixedZone("", offset)), nil
+}
+
+// bcd decodes a binary coded decimal byte
+func bcd(b byte) (int, bool) {
+	hi, lo := b>>4, b&0x0f
+	if hi > 9 || lo > 9 {
+		return 0, false
+	}
+	return int(hi)*10 + int(lo), true
+}
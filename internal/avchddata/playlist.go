@@ -0,0 +1,87 @@
+package avchddata
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// maxPlaylistSize bounds the size of the clip info and playlist files that are read
+	maxPlaylistSize = 1 << 20
+)
+
+// getTimeFromPlaylists returns the recording date from the clip info and playlist files of the clip referenced in the
+// provided path. AVCHD stores clips in BDMV/STREAM, their clip info in BDMV/CLIPINF and the playlists referencing them
+// in BDMV/PLAYLIST. The makers private data of these files holds the same MDPM entries as the video stream.
+// Ref: https://en.wikipedia.org/wiki/AVCHD#File_structure
+func getTimeFromPlaylists(path string) (time.Time, error) {
+	streamDir := filepath.Dir(path)
+	if !strings.EqualFold(filepath.Base(streamDir), "STREAM") {
+		return time.Time{}, errors.New("media is not in an AVCHD directory structure")
+	}
+	bdmvDir := filepath.Dir(streamDir)
+	clip := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+
+	candidates := findFiles(filepath.Join(bdmvDir, "CLIPINF"), func(name string) bool {
+		return strings.EqualFold(name, clip+".cpi")
+	})
+	candidates = append(candidates, findFiles(filepath.Join(bdmvDir, "PLAYLIST"), func(name string) bool {
+		return strings.EqualFold(filepath.Ext(name), ".mpl")
+	})...)
+
+	// playlists reference clips by name followed by the M2TS codec identifier
+	clipRef := []byte(clip + "M2TS")
+	for _, candidate := range candidates {
+		data, err := readFile(candidate)
+		if err != nil {
+			continue
+		}
+		if strings.EqualFold(filepath.Ext(candidate), ".mpl") && !bytes.Contains(data, clipRef) {
+			continue
+		}
+		if t, err := getTimeFromMDPM(data); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errMDPMNotFound
+}
+
+// findFiles returns the files matching the provided name filter in a directory. The directory name is matched
+// case-insensitive since the structure is often copied from FAT formatted cards.
+func findFiles(dir string, match func(string) bool) []string {
+	entries, err := os.ReadDir(filepath.Dir(dir))
+	if err != nil {
+		return nil
+	}
+	var files []string
+	for _, e := range entries {
+		if !e.IsDir() || !strings.EqualFold(e.Name(), filepath.Base(dir)) {
+			continue
+		}
+		actualDir := filepath.Join(filepath.Dir(dir), e.Name())
+		children, err := os.ReadDir(actualDir)
+		if err != nil {
+			continue
+		}
+		for _, c := range children {
+			if !c.IsDir() && match(c.Name()) {
+				files = append(files, filepath.Join(actualDir, c.Name()))
+			}
+		}
+	}
+	return files
+}
+
+func readFile(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(io.LimitReader(f, maxPlaylistSize))
+}
@@ -0,0 +1,277 @@
+package clockskew
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dtrejod/goexif/internal/exifdata"
+)
+
+var (
+	// rangeLayouts are the layouts of the rule date range. A date without a time covers the whole day.
+	rangeLayouts = []struct {
+		layout  string
+		dayOnly bool
+	}{
+		{layout: "2006-01-02T15:04:05"},
+		{layout: "2006-01-02 15:04:05"},
+		{layout: time.DateOnly, dayOnly: true},
+	}
+
+	// clockLayouts are the layouts of the time shown by a reference clock photo. A time without a date is the time of
+	// day nearest to the camera date.
+	clockLayouts = []struct {
+		layout   string
+		timeOnly bool
+	}{
+		{layout: "2006-01-02 15:04:05"},
+		{layout: "2006-01-02 15:04"},
+		{layout: "2006-01-02T15:04:05"},
+		{layout: "15:04:05", timeOnly: true},
+		{layout: "15:04", timeOnly: true},
+	}
+
+	errInvalidRule = errors.New("invalid clock skew rule")
+)
+
+// Rules are clock skew corrections for cameras whose clock was set wrong
+type Rules []Rule
+
+// Rule shifts the dates of media taken by a camera. Empty camera fields match any camera, but a rule must set at least
+// one camera field or AnyCamera.
+type Rule struct {
+	Make             string
+	Model            string
+	BodySerialNumber string
+	// AnyCamera is true for a rule without camera fields that applies to media of every camera, including media without
+	// camera metadata such as MP3 audio
+	AnyCamera bool
+	// From and To limit the rule to media the camera dated within the range, inclusive. The range is compared with the
+	// wall clock of the camera date. Zero times leave the range open.
+	From time.Time
+	To   time.Time
+	// Years, Months and Days are added to the camera date before Offset. They correct calendar errors, such as a clock
+	// set exactly a year off, independent of leap days.
+	Years  int
+	Months int
+	Days   int
+	// Offset is added to the camera date
+	Offset time.Duration
+}
+
+// ruleJSON is the encoding of a Rule in a rule file
+type ruleJSON struct {
+	Make             string `json:"make,omitempty"`
+	Model            string `json:"model,omitempty"`
+	BodySerialNumber string `json:"bodySerialNumber,omitempty"`
+	AnyCamera        bool   `json:"anyCamera,omitempty"`
+	From             string `json:"from,omitempty"`
+	To               string `json:"to,omitempty"`
+	Years            int    `json:"years,omitempty"`
+	Months           int    `json:"months,omitempty"`
+	Days             int    `json:"days,omitempty"`
+	Offset           string `json:"offset,omitempty"`
+}
+
+// Load returns the rules from the provided JSON rule file. The file is a list of rules, e.g.
+//
+//	[{"make": "Canon", "model": "Canon EOS 5D", "from": "2019-01-01", "to": "2019-06-30", "offset": "-1h30m"}]
+func Load(path string) (Rules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var rules Rules
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return rules, nil
+}
+
+// Correct returns the provided camera date shifted by the first rule that matches the camera and date. The boolean
+// is false when no rule matches.
+func (r Rules) Correct(cam exifdata.Camera, ts time.Time) (time.Time, bool) {
+	for _, rule := range r {
+		if rule.Matches(cam, ts) {
+			return rule.Apply(ts), true
+		}
+	}
+	return ts, false
+}
+
+// Matches returns true if the rule applies to media taken by the provided camera at the provided camera date. A rule
+// without camera fields only matches when AnyCamera is set.
+func (r Rule) Matches(cam exifdata.Camera, ts time.Time) bool {
+	if !r.hasCamera() && !r.AnyCamera {
+		return false
+	}
+	if !matchesField(r.Make, cam.Make) || !matchesField(r.Model, cam.Model) ||
+		!matchesField(r.BodySerialNumber, cam.BodySerialNumber) {
+		return false
+	}
+
+	wall := wallClock(ts)
+	if !r.From.IsZero() && wall.Before(r.From) {
+		return false
+	}
+	if !r.To.IsZero() && wall.After(r.To) {
+		return false
+	}
+	return true
+}
+
+// Apply returns the provided camera date shifted by the rule
+func (r Rule) Apply(ts time.Time) time.Time {
+	return ts.AddDate(r.Years, r.Months, r.Days).Add(r.Offset)
+}
+
+// MarshalJSON implements json.Marshaler
+func (r Rule) MarshalJSON() ([]byte, error) {
+	rj := ruleJSON{
+		Make:             r.Make,
+		Model:            r.Model,
+		BodySerialNumber: r.BodySerialNumber,
+		AnyCamera:        r.AnyCamera,
+		Years:            r.Years,
+		Months:           r.Months,
+		Days:             r.Days,
+	}
+	if !r.From.IsZero() {
+		rj.From = r.From.Format(rangeLayouts[0].layout)
+	}
+	if !r.To.IsZero() {
+		rj.To = r.To.Format(rangeLayouts[0].layout)
+	}
+	if r.Offset != 0 {
+		rj.Offset = r.Offset.String()
+	}
+	return json.Marshal(rj)
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (r *Rule) UnmarshalJSON(data []byte) error {
+	var rj ruleJSON
+	if err := json.Unmarshal(data, &rj); err != nil {
+		return err
+	}
+
+	rule := Rule{
+		Make:             rj.Make,
+		Model:            rj.Model,
+		BodySerialNumber: rj.BodySerialNumber,
+		AnyCamera:        rj.AnyCamera,
+		Years:            rj.Years,
+		Months:           rj.Months,
+		Days:             rj.Days,
+	}
+	if !rule.hasCamera() && !rule.AnyCamera {
+		return fmt.Errorf("%w: make, model, bodySerialNumber or anyCamera required", errInvalidRule)
+	}
+	var err error
+	if rj.From != "" {
+		if rule.From, _, err = parseRangeDate(rj.From); err != nil {
+			return err
+		}
+	}
+	if rj.To != "" {
+		to, dayOnly, err := parseRangeDate(rj.To)
+		if err != nil {
+			return err
+		}
+		// a date covers the whole day
+		if dayOnly {
+			to = to.Add(24*time.Hour - time.Nanosecond)
+		}
+		rule.To = to
+	}
+	if rj.Offset != "" {
+		if rule.Offset, err = time.ParseDuration(rj.Offset); err != nil {
+			return fmt.Errorf("%w: %v", errInvalidRule, err)
+		}
+	}
+	*r = rule
+	return nil
+}
+
+// ReferenceRule returns the rule correcting the camera that took a reference photo of a clock. The camera date is the
+// date the camera recorded for the photo and shown is the time the clock in the photo shows, e.g. "14:05" or
+// "2024-03-01 14:05". A time of day is taken to be the one nearest to the camera date, so offsets of more than 12 hours
+// need the date shown as well. The rule applies to any camera when the camera is empty.
+func ReferenceRule(cam exifdata.Camera, cameraDate time.Time, shown string) (Rule, error) {
+	actual, err := parseShownTime(cameraDate, shown)
+	if err != nil {
+		return Rule{}, err
+	}
+	rule := Rule{
+		Make:             cam.Make,
+		Model:            cam.Model,
+		BodySerialNumber: cam.BodySerialNumber,
+		Offset:           actual.Sub(cameraDate),
+	}
+	rule.AnyCamera = !rule.hasCamera()
+	return rule, nil
+}
+
+// hasCamera returns true if the rule sets a camera field
+func (r Rule) hasCamera() bool {
+	return strings.TrimSpace(r.Make) != "" || strings.TrimSpace(r.Model) != "" ||
+		strings.TrimSpace(r.BodySerialNumber) != ""
+}
+
+// parseShownTime returns the time shown on a reference clock in the location of the camera date
+func parseShownTime(cameraDate time.Time, shown string) (time.Time, error) {
+	shown = strings.TrimSpace(shown)
+	for _, l := range clockLayouts {
+		t, err := time.ParseInLocation(l.layout, shown, cameraDate.Location())
+		if err != nil {
+			continue
+		}
+		if !l.timeOnly {
+			return t, nil
+		}
+
+		// pick the time of day on the camera's day, or the day before or after, that is nearest the camera date
+		y, m, d := cameraDate.Date()
+		nearest := time.Date(y, m, d, t.Hour(), t.Minute(), t.Second(), 0, cameraDate.Location())
+		for _, days := range []int{-1, 1} {
+			candidate := nearest.AddDate(0, 0, days)
+			if abs(candidate.Sub(cameraDate)) < abs(nearest.Sub(cameraDate)) {
+				nearest = candidate
+			}
+		}
+		return nearest, nil
+	}
+	return time.Time{}, fmt.Errorf("unknown clock time format %q", shown)
+}
+
+func parseRangeDate(s string) (time.Time, bool, error) {
+	for _, l := range rangeLayouts {
+		if t, err := time.Parse(l.layout, strings.TrimSpace(s)); err == nil {
+			return t, l.dayOnly, nil
+		}
+	}
+	return time.Time{}, false, fmt.Errorf("%w: unknown date format %q", errInvalidRule, s)
+}
+
+// matchesField returns true if the rule field is empty or equal to the camera field ignoring case and surrounding
+// whitespace
+func matchesField(rule, camera string) bool {
+	rule = strings.TrimSpace(rule)
+	return rule == "" || strings.EqualFold(rule, strings.TrimSpace(camera))
+}
+
+// wallClock returns the wall clock of the provided time in UTC so it can be compared with the rule date range
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+func abs(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,141 @@
+package clockskew
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dtrejod/goexif/internal/exifdata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[
+  {"make": "Canon", "model": "Canon EOS 5D", "from": "2019-01-01", "to": "2019-06-30", "offset": "-1h30m"},
+  {"bodySerialNumber": "0123", "years": 1},
+  {"model": "DMC-FZ8", "from": "2021-03-04 10:00:00", "offset": "8h"},
+  {"anyCamera": true, "offset": "-1h"}
+]`), 0644))
+
+	rules, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, Rules{
+		{
+			Make:   "Canon",
+			Model:  "Canon EOS 5D",
+			From:   time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC),
+			To:     time.Date(2019, 6, 30, 23, 59, 59, 999999999, time.UTC),
+			Offset: -90 * time.Minute,
+		},
+		{BodySerialNumber: "0123", Years: 1},
+		{Model: "DMC-FZ8", From: time.Date(2021, 3, 4, 10, 0, 0, 0, time.UTC), Offset: 8 * time.Hour},
+		{AnyCamera: true, Offset: -time.Hour},
+	}, rules)
+
+	for _, invalid := range []string{
+		`[{"make": "Canon", "offset": "1 hour"}]`,
+		`[{"make": "Canon", "from": "01/02/2019"}]`,
+		`[{"offset": "1h"}]`,
+		`[{"make": " ", "offset": "1h"}]`,
+		`{"offset": "1h"}`,
+	} {
+		require.NoError(t, os.WriteFile(path, []byte(invalid), 0644))
+		_, err := Load(path)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestCorrect(t *testing.T) {
+	canon := exifdata.Camera{Make: "Canon", Model: "Canon EOS 5D", BodySerialNumber: "0123"}
+	rules := Rules{
+		{
+			Make:   "canon ",
+			Model:  "Canon EOS 5D",
+			From:   time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC),
+			To:     time.Date(2019, 6, 30, 23, 59, 59, 999999999, time.UTC),
+			Offset: -90 * time.Minute,
+		},
+		{BodySerialNumber: "0123", Years: 1},
+	}
+
+	t.Run("within range", func(t *testing.T) {
+		// the range compares the wall clock regardless of the timezone
+		ts := time.Date(2019, 6, 30, 23, 0, 0, 0, time.FixedZone("", -7*60*60))
+		actual, ok := rules.Correct(canon, ts)
+		assert.True(t, ok)
+		assert.Equal(t, time.Date(2019, 6, 30, 21, 30, 0, 0, time.FixedZone("", -7*60*60)), actual)
+	})
+
+	t.Run("outside range falls through", func(t *testing.T) {
+		ts := time.Date(2019, 7, 1, 0, 0, 0, 0, exifdata.UnknownLocation)
+		actual, ok := rules.Correct(canon, ts)
+		assert.True(t, ok)
+		assert.Equal(t, time.Date(2020, 7, 1, 0, 0, 0, 0, exifdata.UnknownLocation), actual)
+	})
+
+	t.Run("other camera", func(t *testing.T) {
+		ts := time.Date(2019, 3, 1, 0, 0, 0, 0, exifdata.UnknownLocation)
+		actual, ok := rules.Correct(exifdata.Camera{Make: "Apple", Model: "iPhone 12"}, ts)
+		assert.False(t, ok)
+		assert.Equal(t, ts, actual)
+	})
+
+	t.Run("rule without camera", func(t *testing.T) {
+		ts := time.Date(2019, 3, 1, 0, 0, 0, 0, exifdata.UnknownLocation)
+		for _, cam := range []exifdata.Camera{canon, {}} {
+			_, ok := Rules{{Offset: time.Hour}}.Correct(cam, ts)
+			assert.False(t, ok, "matches no camera")
+
+			actual, ok := Rules{{AnyCamera: true, Offset: time.Hour}}.Correct(cam, ts)
+			assert.True(t, ok, "any camera")
+			assert.Equal(t, ts.Add(time.Hour), actual)
+		}
+	})
+}
+
+func TestReferenceRule(t *testing.T) {
+	cam := exifdata.Camera{Make: "Canon", Model: "Canon EOS 5D", BodySerialNumber: "0123"}
+	cameraDate := time.Date(2024, 3, 1, 23, 50, 0, 0, exifdata.UnknownLocation)
+
+	for shown, expected := range map[string]time.Duration{
+		"14:05":               -(9*time.Hour + 45*time.Minute),
+		"00:10":               20 * time.Minute,
+		"23:49:30":            -30 * time.Second,
+		"2025-03-01 23:50":    365 * 24 * time.Hour,
+		"2024-02-29 23:50:00": -24 * time.Hour,
+	} {
+		t.Run(shown, func(t *testing.T) {
+			rule, err := ReferenceRule(cam, cameraDate, shown)
+			require.NoError(t, err)
+			assert.Equal(t, Rule{Make: "Canon", Model: "Canon EOS 5D", BodySerialNumber: "0123", Offset: expected}, rule)
+		})
+	}
+
+	rule, err := ReferenceRule(exifdata.Camera{}, cameraDate, "00:10")
+	require.NoError(t, err)
+	assert.Equal(t, Rule{AnyCamera: true, Offset: 20 * time.Minute}, rule, "without camera metadata")
+
+	_, err = ReferenceRule(cam, cameraDate, "quarter past two")
+	assert.Error(t, err)
+}
+
+func TestRuleJSON(t *testing.T) {
+	rule := Rule{
+		Make:   "Canon",
+		From:   time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC),
+		Years:  -1,
+		Offset: 2*time.Hour + 30*time.Minute,
+	}
+
+	data, err := json.Marshal(rule)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"make": "Canon", "from": "2019-01-01T00:00:00", "years": -1, "offset": "2h30m0s"}`, string(data))
+
+	var actual Rule
+	require.NoError(t, json.Unmarshal(data, &actual))
+	assert.Equal(t, rule, actual)
+}
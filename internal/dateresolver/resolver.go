@@ -0,0 +1,329 @@
+package dateresolver
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dtrejod/goexif/internal/clockskew"
+	"github.com/dtrejod/goexif/internal/exifdata"
+	"github.com/dtrejod/goexif/internal/filenamedata"
+	"github.com/dtrejod/goexif/internal/iclouddata"
+	"github.com/dtrejod/goexif/internal/mediatype"
+	"github.com/dtrejod/goexif/internal/takeoutdata"
+	"github.com/dtrejod/goexif/internal/xmpdata"
+)
+
+// Names of the known date sources
+const (
+	// ExifOriginal is the EXIF DateTimeOriginal tag
+	ExifOriginal = "exif-original"
+	// ExifDigitized is the EXIF DateTimeDigitized tag
+	ExifDigitized = "exif-digitized"
+	// IFD0DateTime is the IFD0 DateTime tag. Editing software commonly rewrites it.
+	IFD0DateTime = "ifd0-datetime"
+	// GPS is the EXIF GPS UTC time in the timezone of the GPS coordinates
+	GPS = "gps"
+	// QuickTime is the QuickTime/ISOBMFF metadata of videos and MPEG-4 audio
+	QuickTime = "quicktime"
+	// RIFF is the RIFF metadata of videos and WAV audio
+	RIFF = "riff"
+	// Matroska is the DateUTC of Matroska and WebM videos
+	Matroska = "matroska"
+	// AVCHD is the MDPM recording date of AVCHD videos
+	AVCHD = "avchd"
+	// MOI is the recording date of the MOI sidecar of MPEG-PS camcorder videos
+	MOI = "moi"
+	// ID3 is the ID3v2 recording time of MP3 audio
+	ID3 = "id3"
+	// XMP is the XMP sidecar or embedded XMP packet
+	XMP = "xmp"
+	// Takeout is the photoTakenTime of a Google Takeout JSON sidecar
+	Takeout = "takeout"
+	// ICloud is the originalCreationDate of an iCloud Photos export "Photo Details.csv"
+	ICloud = "icloud"
+	// Filename is a date found in the media's filename
+	Filename = "filename"
+	// ModTime is the last modified time of the file
+	ModTime = "mtime"
+
+	// Container is an alias naming the ContainerSources not listed elsewhere
+	Container = "container"
+)
+
+var (
+	// KnownSources are the names of all known date sources
+	KnownSources = []string{
+		ExifOriginal, ExifDigitized, IFD0DateTime, GPS, QuickTime, RIFF, Matroska, AVCHD, MOI, ID3, XMP, Takeout,
+		ICloud, Filename, ModTime,
+	}
+	// DefaultSources are the date sources tried, in order, when none are configured.
+	DefaultSources = []string{
+		ExifOriginal, ExifDigitized, QuickTime, RIFF, Matroska, AVCHD, MOI, ID3, XMP, Takeout, ICloud,
+	}
+
+	// ContainerSources are the native sources of formats storing their date in container metadata instead of EXIF tags
+	ContainerSources = []string{QuickTime, RIFF, Matroska, AVCHD, MOI, ID3}
+
+	// ErrNotApplicable is returned by a Source that cannot read dates from the provided media
+	ErrNotApplicable = errors.New("date source not applicable to media")
+	// ErrNoDate is returned by a Resolver when no source applies to the provided media
+	ErrNoDate = errors.New("no date source found a date for media")
+	// ErrUnknownSource is returned when configuring an unknown date source
+	ErrUnknownSource = errors.New("unknown date source")
+)
+
+// Media is a media file to resolve the date of
+type Media struct {
+	Path string
+	// Dates reads the date metadata natively stored by the format of the media. Only sources that do not read native
+	// metadata, such as xmp and filename, apply when nil.
+	Dates mediatype.DateExtractor
+}
+
+// CameraReader is implemented by the date extractors of formats recording the camera that took the media
+type CameraReader interface {
+	// GetCamera returns the camera that took the media file in path
+	GetCamera(path string) (exifdata.Camera, error)
+}
+
+// Extractor is a mediatype.DateExtractor reading each date source with a function of the media path
+type Extractor struct {
+	// Dates are the functions reading the date of the media by source name, e.g. exif-original
+	Dates map[string]func(string) (time.Time, error)
+	// Camera reads the camera that took the media. Nil for formats without camera metadata.
+	Camera func(string) (exifdata.Camera, error)
+}
+
+// Sources implements mediatype.DateExtractor. The names are in the order of KnownSources.
+func (e Extractor) Sources() []string {
+	var names []string
+	for _, name := range KnownSources {
+		if _, ok := e.Dates[name]; ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// GetTime implements mediatype.DateExtractor. ErrNotApplicable is returned for sources the format does not store.
+func (e Extractor) GetTime(source, path string) (time.Time, error) {
+	getTime, ok := e.Dates[source]
+	if !ok {
+		return time.Time{}, ErrNotApplicable
+	}
+	return getTime(path)
+}
+
+// GetCamera implements CameraReader
+func (e Extractor) GetCamera(path string) (exifdata.Camera, error) {
+	if e.Camera == nil {
+		return exifdata.Camera{}, ErrNotApplicable
+	}
+	return e.Camera(path)
+}
+
+// Source is a date source of media files
+type Source interface {
+	// Name is the name used to configure the source, e.g. exif-original
+	Name() string
+	// GetTime returns the date of the media. ErrNotApplicable is returned when the source cannot read the media.
+	GetTime(m Media) (time.Time, error)
+}
+
+// Resolver resolves the date of media from an ordered list of date sources. The first source that finds a valid date
+// wins.
+type Resolver struct {
+	sources   []Source
+	validator *Validator
+	clockSkew clockskew.Rules
+}
+
+// New returns a Resolver that tries the provided sources in order. Dates are validated with NewValidator.
+func New(sources ...Source) *Resolver {
+	return &Resolver{sources: sources, validator: NewValidator()}
+}
+
+// SetValidator sets the Validator dates found by the sources must pass. Nil disables validation.
+func (r *Resolver) SetValidator(v *Validator) {
+	r.validator = v
+}
+
+// NewFromNames returns a Resolver that tries the named sources in order. The filename source uses the provided parser,
+// or the built-in filename patterns when nil.
+//
+// Each format stores its native dates either in EXIF tags or in a single container source such as quicktime. The
+// Container alias is replaced by the ContainerSources the names do not list, so a list choosing between EXIF tags can
+// keep the dates of videos and audio without naming every container source.
+func NewFromNames(names []string, filenameParser *filenamedata.Parser) (*Resolver, error) {
+	listed := make(map[string]bool, len(names))
+	for _, name := range names {
+		listed[strings.ToLower(strings.TrimSpace(name))] = true
+	}
+
+	sources := make([]Source, 0, len(names)+len(ContainerSources))
+	for _, name := range names {
+		if strings.ToLower(strings.TrimSpace(name)) == Container {
+			for _, c := range ContainerSources {
+				if !listed[c] {
+					listed[c] = true
+					sources = append(sources, nativeSource(c))
+				}
+			}
+			continue
+		}
+		s, err := NewSource(name, filenameParser)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, s)
+	}
+	return New(sources...), nil
+}
+
+// Defaults returns DefaultSources extended with the optional sources. GPS time is preferred over all other sources while
+// the filename and last modified time are fallbacks, in that order.
+func Defaults(useGPSTime, useFilename, useModTime bool) []string {
+	names := make([]string, 0, len(DefaultSources)+3)
+	if useGPSTime {
+		names = append(names, GPS)
+	}
+	names = append(names, DefaultSources...)
+	if useFilename {
+		names = append(names, Filename)
+	}
+	if useModTime {
+		names = append(names, ModTime)
+	}
+	return names
+}
+
+// SetClockSkew sets the rules correcting dates recorded by camera clocks that were set wrong
+func (r *Resolver) SetClockSkew(rules clockskew.Rules) {
+	r.clockSkew = rules
+}
+
+// Resolve returns the date of the provided media and the name of the source it was found in. Dates recorded by the
+// camera clock are corrected by the clock skew rules. Sources finding an invalid date are skipped. When no source
+// finds a date, then the error of the first applicable source is returned.
+func (r *Resolver) Resolve(m Media) (time.Time, string, error) {
+	var firstErr error
+	for _, s := range r.sources {
+		ts, err := s.GetTime(m)
+		if err == nil && len(r.clockSkew) > 0 && IsCameraClock(s.Name()) {
+			ts, _ = r.clockSkew.Correct(GetCamera(m), ts)
+		}
+		if err == nil && r.validator != nil {
+			err = r.validator.Validate(ts)
+		}
+		if err == nil {
+			return ts, s.Name(), nil
+		}
+		if firstErr == nil && !errors.Is(err, ErrNotApplicable) {
+			firstErr = err
+		}
+	}
+	if firstErr == nil {
+		firstErr = ErrNoDate
+	}
+	return time.Time{}, "", firstErr
+}
+
+// Names returns the names of the sources in the order they are tried
+func (r *Resolver) Names() []string {
+	names := make([]string, 0, len(r.sources))
+	for _, s := range r.sources {
+		names = append(names, s.Name())
+	}
+	return names
+}
+
+func (r *Resolver) String() string {
+	return strings.Join(r.Names(), ",")
+}
+
+// IsCameraClock returns true if the named source reads dates recorded by the camera clock. Dates from GPS, export
+// metadata, filenames and the filesystem are not affected by a wrong camera clock.
+func IsCameraClock(name string) bool {
+	switch name {
+	case ExifOriginal, ExifDigitized, IFD0DateTime, QuickTime, RIFF, Matroska, AVCHD, MOI, ID3:
+		return true
+	}
+	return false
+}
+
+// GetCamera returns the camera that took the provided media. The camera is empty when the media has no camera
+// metadata.
+func GetCamera(m Media) exifdata.Camera {
+	var cam exifdata.Camera
+	if r, ok := m.Dates.(CameraReader); ok {
+		cam, _ = r.GetCamera(m.Path)
+	}
+	return cam
+}
+
+// NewSource returns the known date source with the provided name. Names are matched case-insensitive. The filename
+// source uses the provided parser, or the built-in filename patterns when nil.
+func NewSource(name string, filenameParser *filenamedata.Parser) (Source, error) {
+	switch name = strings.ToLower(strings.TrimSpace(name)); name {
+	case ExifOriginal, ExifDigitized, IFD0DateTime, GPS, QuickTime, RIFF, Matroska, AVCHD, MOI, ID3:
+		return nativeSource(name), nil
+	case XMP:
+		return newSource(name, xmpdata.GetTime), nil
+	case Takeout:
+		return newSource(name, takeoutdata.GetTime), nil
+	case ICloud:
+		return newSource(name, iclouddata.GetTime), nil
+	case Filename:
+		if filenameParser == nil {
+			return newSource(name, filenamedata.GetTime), nil
+		}
+		return newSource(name, filenameParser.GetTime), nil
+	case ModTime:
+		return newSource(name, getModTime), nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownSource, name)
+}
+
+// source is a Source reading the date with a function of the media path
+type source struct {
+	name    string
+	getTime func(string) (time.Time, error)
+}
+
+func newSource(name string, getTime func(string) (time.Time, error)) *source {
+	return &source{name: name, getTime: getTime}
+}
+
+func (s *source) Name() string {
+	return s.name
+}
+
+func (s *source) GetTime(m Media) (time.Time, error) {
+	return s.getTime(m.Path)
+}
+
+// nativeSource is a Source reading the date metadata natively stored in media with the date extractor of its format
+type nativeSource string
+
+func (s nativeSource) Name() string {
+	return string(s)
+}
+
+func (s nativeSource) GetTime(m Media) (time.Time, error) {
+	if m.Dates == nil || !slices.Contains(m.Dates.Sources(), string(s)) {
+		return time.Time{}, ErrNotApplicable
+	}
+	return m.Dates.GetTime(string(s), m.Path)
+}
+
+func getModTime(path string) (time.Time, error) {
+	f, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return f.ModTime().UTC(), nil
+}
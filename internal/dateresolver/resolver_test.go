@@ -0,0 +1,149 @@
+package dateresolver
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dtrejod/goexif/internal/clockskew"
+	"github.com/dtrejod/goexif/internal/exifdata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolve(t *testing.T) {
+	ts := time.Date(2020, 6, 26, 23, 19, 26, 0, time.UTC)
+	errMissing := errors.New("missing")
+	media := Media{Path: "IMG_1234.jpg", Dates: Extractor{
+		Dates: map[string]func(string) (time.Time, error){
+			ExifOriginal: func(string) (time.Time, error) { return time.Time{}, errMissing },
+		},
+	}}
+
+	t.Run("first source with a date wins", func(t *testing.T) {
+		r := New(
+			newSource("missing", func(string) (time.Time, error) { return time.Time{}, errMissing }),
+			nativeSource(QuickTime),
+			newSource("found", func(string) (time.Time, error) { return ts, nil }),
+			newSource("later", func(string) (time.Time, error) { return ts.Add(time.Hour), nil }),
+		)
+
+		actual, source, err := r.Resolve(media)
+		require.NoError(t, err)
+		assert.Equal(t, ts, actual)
+		assert.Equal(t, "found", source)
+	})
+
+	t.Run("first applicable error is returned", func(t *testing.T) {
+		r := New(nativeSource(QuickTime), nativeSource(ExifOriginal))
+
+		_, _, err := r.Resolve(media)
+		assert.ErrorIs(t, err, errMissing)
+	})
+
+	t.Run("no applicable source", func(t *testing.T) {
+		_, _, err := New().Resolve(media)
+		assert.ErrorIs(t, err, ErrNoDate)
+	})
+
+	t.Run("mtime", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "noexif.png")
+		require.NoError(t, os.WriteFile(path, []byte("not an image"), 0644))
+		require.NoError(t, os.Chtimes(path, ts, ts))
+
+		r, err := NewFromNames([]string{ExifOriginal, XMP, Filename, ModTime}, nil)
+		require.NoError(t, err)
+
+		actual, source, err := r.Resolve(Media{Path: path, Dates: Extractor{}})
+		require.NoError(t, err)
+		assert.Equal(t, ts, actual)
+		assert.Equal(t, ModTime, source)
+	})
+}
+
+func TestNewFromNames(t *testing.T) {
+	r, err := NewFromNames([]string{"xmp", " EXIF-Original", "ifd0-datetime"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{XMP, ExifOriginal, IFD0DateTime}, r.Names(), "listed sources only")
+	assert.Equal(t, "xmp,exif-original,ifd0-datetime", r.String())
+
+	r, err = NewFromNames([]string{ExifOriginal, " Container", IFD0DateTime}, nil)
+	require.NoError(t, err)
+	assert.Equal(t,
+		[]string{ExifOriginal, QuickTime, RIFF, Matroska, AVCHD, MOI, ID3, IFD0DateTime},
+		r.Names(),
+		"container alias")
+
+	r, err = NewFromNames([]string{ExifOriginal, Container, ModTime, RIFF}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{ExifOriginal, QuickTime, Matroska, AVCHD, MOI, ID3, ModTime, RIFF}, r.Names(),
+		"listed container sources keep their position")
+
+	r, err = NewFromNames(DefaultSources, nil)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultSources, r.Names(), "defaults")
+
+	for _, name := range KnownSources {
+		_, err := NewSource(name, nil)
+		assert.NoError(t, err, name)
+	}
+
+	_, err = NewFromNames([]string{ExifOriginal, "exif-bogus"}, nil)
+	assert.ErrorIs(t, err, ErrUnknownSource)
+}
+
+func TestDefaults(t *testing.T) {
+	assert.Equal(t, DefaultSources, Defaults(false, false, false))
+	assert.Equal(t,
+		[]string{
+			GPS, ExifOriginal, ExifDigitized, QuickTime, RIFF, Matroska, AVCHD, MOI, ID3, XMP, Takeout, ICloud,
+			Filename, ModTime,
+		},
+		Defaults(true, true, true))
+}
+
+func TestExtractor(t *testing.T) {
+	ts := time.Date(2020, 6, 26, 23, 19, 26, 0, time.UTC)
+	cam := exifdata.Camera{Make: "Canon"}
+	e := Extractor{
+		Dates: map[string]func(string) (time.Time, error){
+			GPS:          func(string) (time.Time, error) { return ts.Add(time.Hour), nil },
+			ExifOriginal: func(string) (time.Time, error) { return ts, nil },
+		},
+		Camera: func(string) (exifdata.Camera, error) { return cam, nil },
+	}
+	assert.Equal(t, []string{ExifOriginal, GPS}, e.Sources())
+
+	actual, source, err := New(nativeSource(QuickTime), nativeSource(ExifOriginal)).Resolve(Media{Dates: e})
+	require.NoError(t, err)
+	assert.Equal(t, ts, actual)
+	assert.Equal(t, ExifOriginal, source)
+
+	assert.Equal(t, cam, GetCamera(Media{Dates: e}))
+	assert.Equal(t, exifdata.Camera{}, GetCamera(Media{Dates: Extractor{}}))
+	assert.Equal(t, exifdata.Camera{}, GetCamera(Media{}))
+
+	_, _, err = New(nativeSource(ExifOriginal)).Resolve(Media{})
+	assert.ErrorIs(t, err, ErrNoDate)
+}
+
+func TestResolveClockSkew(t *testing.T) {
+	ts := time.Date(2020, 6, 26, 23, 19, 26, 0, time.UTC)
+	media := Media{Path: "IMG_1234.jpg"}
+	getTime := func(string) (time.Time, error) { return ts, nil }
+
+	r := New(newSource(ExifOriginal, getTime))
+	r.SetClockSkew(clockskew.Rules{{AnyCamera: true, Offset: time.Hour}})
+	actual, _, err := r.Resolve(media)
+	require.NoError(t, err)
+	assert.Equal(t, ts.Add(time.Hour), actual)
+
+	// dates not recorded by the camera clock are not shifted
+	r = New(newSource(XMP, getTime))
+	r.SetClockSkew(clockskew.Rules{{AnyCamera: true, Offset: time.Hour}})
+	actual, _, err = r.Resolve(media)
+	require.NoError(t, err)
+	assert.Equal(t, ts, actual)
+}
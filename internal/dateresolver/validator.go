@@ -0,0 +1,79 @@
+package dateresolver
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// maxFutureSkew is how far into the future dates are valid by default. It covers media dated in a timezone ahead
+	// of the local clock.
+	maxFutureSkew = 24 * time.Hour
+	// suspiciousDateLayout is the wall clock precision suspicious dates are compared with
+	suspiciousDateLayout = "2006-01-02 15:04:05"
+)
+
+var (
+	// DefaultMinDate is the earliest valid date by default
+	DefaultMinDate = time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)
+	// CameraResetDate is the date many cameras fall back to after a clock reset
+	CameraResetDate = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	// DefaultSuspiciousDates are dates written by devices with an unset or reset clock, or by software writing a
+	// zero timestamp. Media dated with them is treated as having no date.
+	DefaultSuspiciousDates = []time.Time{
+		// Unix epoch
+		time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC),
+		// QuickTime epoch written as a zero mvhd creation time
+		time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC),
+		// camera defaults after a clock reset
+		CameraResetDate,
+		// DOS/FAT epoch
+		time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	// ErrInvalidDate is returned when a date source found a bogus date
+	ErrInvalidDate = errors.New("invalid date")
+)
+
+// Validator rejects bogus and sentinel dates found by date sources so the next source is tried instead.
+type Validator struct {
+	// Min is the earliest valid date. There is no lower bound when zero.
+	Min time.Time
+	// Max is the latest valid date. There is no upper bound when zero.
+	Max time.Time
+	// SuspiciousDates are invalid dates. A date is suspicious when either its wall clock, to the second, or its
+	// instant matches, so both camera defaults without a timezone and zero timestamps shifted into a timezone match.
+	SuspiciousDates []time.Time
+}
+
+// NewValidator returns a Validator rejecting dates before DefaultMinDate, more than a day in the future or one of the
+// DefaultSuspiciousDates.
+func NewValidator() *Validator {
+	return &Validator{
+		Min:             DefaultMinDate,
+		Max:             time.Now().Add(maxFutureSkew),
+		SuspiciousDates: DefaultSuspiciousDates,
+	}
+}
+
+// Validate returns ErrInvalidDate if the provided date is out of bounds or suspicious
+func (v *Validator) Validate(ts time.Time) error {
+	if ts.IsZero() {
+		return fmt.Errorf("%w: zero date", ErrInvalidDate)
+	}
+	if !v.Min.IsZero() && ts.Before(v.Min) {
+		return fmt.Errorf("%w: %s is before %s", ErrInvalidDate, ts, v.Min)
+	}
+	if !v.Max.IsZero() && ts.After(v.Max) {
+		return fmt.Errorf("%w: %s is after %s", ErrInvalidDate, ts, v.Max)
+	}
+
+	wallClock := ts.Format(suspiciousDateLayout)
+	for _, s := range v.SuspiciousDates {
+		if ts.Equal(s) || wallClock == s.Format(suspiciousDateLayout) {
+			return fmt.Errorf("%w: %s is a known default date", ErrInvalidDate, ts)
+		}
+	}
+	return nil
+}
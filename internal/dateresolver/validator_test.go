@@ -0,0 +1,71 @@
+package dateresolver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dtrejod/goexif/internal/exifdata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	v := NewValidator()
+
+	for name, ts := range map[string]time.Time{
+		"zero":                   {},
+		"before min":             time.Date(1850, 1, 1, 0, 0, 0, 0, time.UTC),
+		"future":                 time.Now().Add(7 * 24 * time.Hour),
+		"unix epoch":             time.Unix(0, 0).UTC(),
+		"unix epoch in timezone": time.Unix(0, 0).In(time.FixedZone("", -5*60*60)),
+		"quicktime epoch":        time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC),
+		"camera default":         time.Date(2000, 1, 1, 0, 0, 0, 0, exifdata.UnknownLocation),
+		"camera default offset":  time.Date(2000, 1, 1, 0, 0, 0, 0, time.FixedZone("", 9*60*60)),
+		"dos epoch":              time.Date(1980, 1, 1, 0, 0, 0, 0, exifdata.UnknownLocation),
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorIs(t, v.Validate(ts), ErrInvalidDate)
+		})
+	}
+
+	for name, ts := range map[string]time.Time{
+		"capture time":           time.Date(2020, 6, 26, 23, 19, 26, 0, exifdata.UnknownLocation),
+		"new year after default": time.Date(2000, 1, 1, 0, 0, 1, 0, exifdata.UnknownLocation),
+		"today":                  time.Now(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.NoError(t, v.Validate(ts))
+		})
+	}
+
+	t.Run("custom bounds", func(t *testing.T) {
+		v := &Validator{
+			Min: time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC),
+			Max: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		}
+		assert.ErrorIs(t, v.Validate(time.Date(2009, 12, 31, 0, 0, 0, 0, time.UTC)), ErrInvalidDate)
+		assert.ErrorIs(t, v.Validate(time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)), ErrInvalidDate)
+		assert.NoError(t, v.Validate(time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)))
+		assert.NoError(t, (&Validator{}).Validate(time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)), "no suspicious dates")
+	})
+}
+
+func TestResolveSkipsInvalidDates(t *testing.T) {
+	ts := time.Date(2020, 6, 26, 23, 19, 26, 0, time.UTC)
+	r := New(
+		newSource("reset", func(string) (time.Time, error) {
+			return time.Date(2000, 1, 1, 0, 0, 0, 0, exifdata.UnknownLocation), nil
+		}),
+		newSource("found", func(string) (time.Time, error) { return ts, nil }),
+	)
+
+	actual, source, err := r.Resolve(Media{Path: "IMG_1234.jpg"})
+	require.NoError(t, err)
+	assert.Equal(t, ts, actual)
+	assert.Equal(t, "found", source)
+
+	r.SetValidator(nil)
+	_, source, err = r.Resolve(Media{Path: "IMG_1234.jpg"})
+	require.NoError(t, err)
+	assert.Equal(t, "reset", source)
+}
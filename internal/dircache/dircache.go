@@ -0,0 +1,51 @@
+package dircache
+
+import (
+	"sync"
+)
+
+// Cache holds data read once per directory, such as a directory listing or export metadata shared by every media file
+// in the directory. Only the most recently used directories are kept. Directories are walked depth first, so the
+// directories on the current path stay cached as long as the walk is not deeper than the cache size.
+type Cache[T any] struct {
+	mu   sync.Mutex
+	size int
+	load func(dir string) (T, error)
+	// dirs are the cached directories from least to most recently used
+	dirs []entry[T]
+}
+
+type entry[T any] struct {
+	dir  string
+	data T
+}
+
+// New returns a Cache of at most size directories whose data is read with the provided load function. Load errors are
+// not cached.
+func New[T any](size int, load func(dir string) (T, error)) *Cache[T] {
+	return &Cache[T]{size: max(size, 1), load: load}
+}
+
+// Get returns the data of the provided directory, reading it when it is not cached
+func (c *Cache[T]) Get(dir string) (T, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, e := range c.dirs {
+		if e.dir == dir {
+			c.dirs = append(append(c.dirs[:i], c.dirs[i+1:]...), e)
+			return e.data, nil
+		}
+	}
+
+	data, err := c.load(dir)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	if len(c.dirs) == c.size {
+		c.dirs = c.dirs[1:]
+	}
+	c.dirs = append(c.dirs, entry[T]{dir: dir, data: data})
+	return data, nil
+}
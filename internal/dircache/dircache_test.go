@@ -0,0 +1,44 @@
+package dircache
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache(t *testing.T) {
+	loads := make(map[string]int)
+	c := New(2, func(dir string) (string, error) {
+		loads[dir]++
+		if dir == "bad" {
+			return "", errors.New("unreadable")
+		}
+		return "data of " + dir, nil
+	})
+	get := func(dir string) {
+		t.Helper()
+		data, err := c.Get(dir)
+		require.NoError(t, err)
+		assert.Equal(t, "data of "+dir, data)
+	}
+
+	get("a")
+	get("a")
+	assert.Equal(t, 1, loads["a"], "cached")
+
+	get("b")
+	get("a")
+	get("c")
+	get("a")
+	assert.Equal(t, 1, loads["a"], "most recently used is kept")
+	get("b")
+	assert.Equal(t, 2, loads["b"], "least recently used is evicted")
+
+	_, err := c.Get("bad")
+	assert.Error(t, err)
+	_, err = c.Get("bad")
+	assert.Error(t, err)
+	assert.Equal(t, 2, loads["bad"], "errors are not cached")
+}
@@ -0,0 +1,105 @@
+package exifdata
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"strings"
+
+	"github.com/dsoprea/go-exif/v3"
+	exifundefined "github.com/dsoprea/go-exif/v3/undefined"
+)
+
+const (
+	// appleMakerNoteByteOrderOffset is the offset of the byte order ("MM" or "II") in an Apple MakerNote following the
+	// "Apple iOS\0" signature and version(2)
+	appleMakerNoteByteOrderOffset = 12
+	// appleMakerNoteIfdOffset is the offset of the IFD in an Apple MakerNote following the byte order(2)
+	appleMakerNoteIfdOffset = 14
+	// appleContentIdentifierTag is the Apple MakerNote tag that identifies the media of a Live Photo. The paired video
+	// records the same identifier.
+	// Ref: https://exiftool.org/TagNames/Apple.html
+	appleContentIdentifierTag = 0x0011
+	// asciiType is the EXIF ASCII tag type
+	asciiType = 2
+)
+
+var (
+	appleMakerNoteSignature = []byte("Apple iOS\x00")
+
+	errNoContentIdentifier = errors.New("could not find Apple ContentIdentifier")
+)
+
+// GetContentIdentifier returns the Apple ContentIdentifier from the MakerNote of media referenced in the provided path.
+// The image and video of a Live Photo share the same ContentIdentifier.
+func GetContentIdentifier(path string) (string, error) {
+	rootIfd, err := getRootIfd(path)
+	if err != nil {
+		return "", err
+	}
+
+	exifIfd, err := exif.FindIfdFromRootIfd(rootIfd, "IFD/Exif")
+	if err != nil {
+		return "", errors.New("IFD/Exif not found")
+	}
+	results, err := exifIfd.FindTagWithName("MakerNote")
+	if err != nil || len(results) != 1 {
+		return "", errors.New("could not find MakerNote tag")
+	}
+	value, err := results[0].Value()
+	if err != nil {
+		return "", err
+	}
+
+	var makerNote []byte
+	switch mn := value.(type) {
+	case exifundefined.Tag927CMakerNote:
+		makerNote = mn.MakerNoteBytes
+	case []byte:
+		makerNote = mn
+	default:
+		return "", errors.New("unexpected MakerNote value")
+	}
+	return getAppleContentIdentifier(makerNote)
+}
+
+// getAppleContentIdentifier returns the ContentIdentifier tag from an Apple MakerNote. The MakerNote is an IFD in the
+// byte order recorded in its header, and its value offsets are relative to the start of the MakerNote.
+func getAppleContentIdentifier(makerNote []byte) (string, error) {
+	if !bytes.HasPrefix(makerNote, appleMakerNoteSignature) || len(makerNote) < appleMakerNoteIfdOffset+2 {
+		return "", errors.New("not an Apple MakerNote")
+	}
+
+	var order binary.ByteOrder
+	switch string(makerNote[appleMakerNoteByteOrderOffset:appleMakerNoteIfdOffset]) {
+	case "MM":
+		order = binary.BigEndian
+	case "II":
+		order = binary.LittleEndian
+	default:
+		return "", errors.New("unknown Apple MakerNote byte order")
+	}
+	count := int(order.Uint16(makerNote[appleMakerNoteIfdOffset:]))
+	for i := 0; i < count; i++ {
+		entry := appleMakerNoteIfdOffset + 2 + i*12
+		if entry+12 > len(makerNote) {
+			break
+		}
+		if order.Uint16(makerNote[entry:]) != appleContentIdentifierTag || order.Uint16(makerNote[entry+2:]) != asciiType {
+			continue
+		}
+
+		size := int(order.Uint32(makerNote[entry+4:]))
+		start := entry + 8
+		if size > 4 {
+			start = int(order.Uint32(makerNote[entry+8:]))
+		}
+		if start < 0 || size < 0 || start+size > len(makerNote) {
+			return "", errors.New("invalid Apple ContentIdentifier offset")
+		}
+		if id := strings.TrimRight(string(makerNote[start:start+size]), "\x00"); id != "" {
+			return id, nil
+		}
+	}
+	return "", errNoContentIdentifier
+}
@@ -0,0 +1,86 @@
+package exifdata
+
+import (
+	"encoding/binary"
+	"testing"
+
+	exifundefined "github.com/dsoprea/go-exif/v3/undefined"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// appleMakerNote returns an Apple MakerNote in the provided byte order with the provided ASCII tags
+func appleMakerNote(order binary.AppendByteOrder, tags map[uint16]string) []byte {
+	out := append([]byte{}, appleMakerNoteSignature...)
+	out = append(out, 0x00, 0x01)
+	if order == binary.LittleEndian {
+		out = append(out, 'I', 'I')
+	} else {
+		out = append(out, 'M', 'M')
+	}
+	out = order.AppendUint16(out, uint16(len(tags)))
+
+	valueOffset := len(out) + len(tags)*12 + 4
+	var values []byte
+	for tag, value := range tags {
+		data := append([]byte(value), 0)
+		out = order.AppendUint16(out, tag)
+		out = order.AppendUint16(out, asciiType)
+		out = order.AppendUint32(out, uint32(len(data)))
+		out = order.AppendUint32(out, uint32(valueOffset+len(values)))
+		values = append(values, data...)
+	}
+	// next IFD offset
+	out = order.AppendUint32(out, 0)
+	return append(out, values...)
+}
+
+func TestGetContentIdentifier(t *testing.T) {
+	id := "8E0C8AA8-5D4E-4B0F-9E6C-3F1B2E1F6E9A"
+
+	for name, order := range map[string]binary.AppendByteOrder{
+		"big-endian":    binary.BigEndian,
+		"little-endian": binary.LittleEndian,
+	} {
+		t.Run("with "+name+" apple maker note", func(t *testing.T) {
+			path := writeExif(t,
+				exifTag{"IFD/Exif", "DateTimeOriginal", "2023:10:01 12:00:00"},
+				exifTag{"IFD/Exif", "MakerNote", exifundefined.Tag927CMakerNote{
+					MakerNoteBytes: appleMakerNote(order, map[uint16]string{0x000c: "ignored", appleContentIdentifierTag: id}),
+				}},
+			)
+
+			actual, err := GetContentIdentifier(path)
+			require.NoError(t, err)
+			assert.Equal(t, id, actual)
+		})
+	}
+
+	t.Run("with unknown byte order", func(t *testing.T) {
+		makerNote := appleMakerNote(binary.BigEndian, map[uint16]string{appleContentIdentifierTag: id})
+		copy(makerNote[appleMakerNoteByteOrderOffset:], "XX")
+
+		_, err := getAppleContentIdentifier(makerNote)
+		assert.Error(t, err)
+	})
+
+	t.Run("without content identifier", func(t *testing.T) {
+		path := writeExif(t,
+			exifTag{"IFD/Exif", "MakerNote", exifundefined.Tag927CMakerNote{
+				MakerNoteBytes: appleMakerNote(binary.BigEndian, map[uint16]string{0x000c: "ignored"}),
+			}},
+		)
+
+		_, err := GetContentIdentifier(path)
+		assert.Error(t, err)
+	})
+
+	t.Run("with other maker note", func(t *testing.T) {
+		path := writeExif(t,
+			exifTag{"IFD/Exif", "MakerNote", exifundefined.Tag927CMakerNote{MakerNoteBytes: []byte("Nikon\x00\x02\x10")}},
+		)
+
+		_, err := GetContentIdentifier(path)
+		assert.Error(t, err)
+	})
+}
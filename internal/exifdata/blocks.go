@@ -0,0 +1,198 @@
+package exifdata
+
+import (
+	"bytes"
+	"errors"
+	"time"
+
+	"github.com/dsoprea/go-exif/v3"
+	exifcommon "github.com/dsoprea/go-exif/v3/common"
+)
+
+// TIFFBlocks are EXIF IFDs stored in separate TIFF blocks instead of a single EXIF block, such as the CMT1, CMT2 and
+// CMT4 boxes of Canon CR3 files. Each block is a TIFF header followed by the tags of a single IFD. A missing block is
+// nil.
+type TIFFBlocks struct {
+	// IFD0 holds the tags of IFD0
+	IFD0 []byte
+	// Exif holds the tags of IFD/Exif
+	Exif []byte
+	// GPS holds the tags of IFD/GPSInfo
+	GPS []byte
+}
+
+// GetTime returns the EXIF metadata Datetime from the blocks. See GetTime.
+func (b TIFFBlocks) GetTime() (time.Time, error) {
+	return b.getTime(dateTags...)
+}
+
+// GetDateTimeOriginal returns the EXIF DateTimeOriginal from the blocks. See GetTime.
+func (b TIFFBlocks) GetDateTimeOriginal() (time.Time, error) {
+	return b.getTime(dateTimeOriginalTag)
+}
+
+// GetDateTimeDigitized returns the EXIF DateTimeDigitized from the blocks. See GetTime.
+func (b TIFFBlocks) GetDateTimeDigitized() (time.Time, error) {
+	return b.getTime(dateTimeDigitizedTag)
+}
+
+// GetDateTime returns the IFD0 DateTime from the blocks. See GetDateTime.
+func (b TIFFBlocks) GetDateTime() (time.Time, error) {
+	return b.getTime(dateTimeTag)
+}
+
+// GetGPSTime returns the GPS metadata Datetime from the blocks. See GetGPSTime.
+func (b TIFFBlocks) GetGPSTime() (time.Time, error) {
+	if len(b.GPS) == 0 {
+		return time.Time{}, errors.New("IFD/GPSInfo not found")
+	}
+	gpsIfd, err := scanIfd(b.GPS, exifcommon.IfdGpsInfoStandardIfdIdentity)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return getGPSTimeFromIfd(gpsIfd)
+}
+
+// GetCamera returns the camera Make, Model and BodySerialNumber from the blocks. See GetCamera.
+func (b TIFFBlocks) GetCamera() (Camera, error) {
+	rootIfd, exifIfd, err := b.ifds()
+	if err != nil {
+		return Camera{}, err
+	}
+	return getCameraFromIfds(rootIfd, exifIfd)
+}
+
+func (b TIFFBlocks) getTime(tags ...dateTag) (time.Time, error) {
+	rootIfd, exifIfd, err := b.ifds()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return getTimeFromIfds(rootIfd, exifIfd, tags...)
+}
+
+// ifds parses the blocks. At least one block must be found.
+func (b TIFFBlocks) ifds() (tagFinder, tagFinder, error) {
+	var rootIfd, exifIfd tagFinder
+	if len(b.IFD0) > 0 {
+		ifd, err := getRootIfdFromReader(bytes.NewReader(b.IFD0))
+		if err != nil {
+			return nil, nil, err
+		}
+		rootIfd = ifd
+	}
+	if len(b.Exif) > 0 {
+		ifd, err := scanIfd(b.Exif, exifcommon.IfdExifStandardIfdIdentity)
+		if err != nil {
+			return nil, nil, err
+		}
+		exifIfd = ifd
+	}
+	if rootIfd == nil && exifIfd == nil {
+		return nil, nil, errors.New("no tiff blocks found")
+	}
+	return rootIfd, exifIfd, nil
+}
+
+// scannedIfd is the first IFD of a TIFF block parsed as the IFD with the provided identity rather than as IFD0
+type scannedIfd struct {
+	ii       *exifcommon.IfdIdentity
+	tagIndex *exif.TagIndex
+	entries  []*exif.IfdTagEntry
+}
+
+// scanIfd parses the first IFD of the TIFF block as the IFD with the provided identity
+func scanIfd(data []byte, ii *exifcommon.IfdIdentity) (*scannedIfd, error) {
+	eh, err := exif.ParseExifHeader(data)
+	if err != nil {
+		return nil, err
+	}
+
+	im, err := exifcommon.NewIfdMappingWithStandard()
+	if err != nil {
+		return nil, err
+	}
+	ti := exif.NewTagIndex()
+
+	s := &scannedIfd{ii: ii, tagIndex: ti}
+	ie := exif.NewIfdEnumerate(im, ti, exif.NewExifReadSeekerWithBytes(data), eh.ByteOrder)
+	_, err = ie.Scan(ii, eh.FirstIfdOffset, func(ite *exif.IfdTagEntry) error {
+		// child IFDs are visited too
+		if ite.IfdPath() == ii.UnindexedString() {
+			s.entries = append(s.entries, ite)
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// FindTagWithName implements tagFinder
+func (s *scannedIfd) FindTagWithName(tagName string) ([]*exif.IfdTagEntry, error) {
+	it, err := s.tagIndex.GetWithName(s.ii, tagName)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*exif.IfdTagEntry
+	for _, ite := range s.entries {
+		if ite.TagId() == it.Id {
+			results = append(results, ite)
+		}
+	}
+	if len(results) == 0 {
+		return nil, exif.ErrTagNotFound
+	}
+	return results, nil
+}
+
+// Block is an EXIF block embedded in the structures of a container, such as the EXIF chunk of WebP files. The block is
+// searched for the TIFF header, so a leading "Exif\x00\x00" identifier is allowed.
+type Block []byte
+
+// GetTime returns the EXIF metadata Datetime from the block. See GetTime.
+func (b Block) GetTime() (time.Time, error) {
+	return b.getTime(dateTags...)
+}
+
+// GetDateTimeOriginal returns the EXIF DateTimeOriginal from the block. See GetTime.
+func (b Block) GetDateTimeOriginal() (time.Time, error) {
+	return b.getTime(dateTimeOriginalTag)
+}
+
+// GetDateTimeDigitized returns the EXIF DateTimeDigitized from the block. See GetTime.
+func (b Block) GetDateTimeDigitized() (time.Time, error) {
+	return b.getTime(dateTimeDigitizedTag)
+}
+
+// GetDateTime returns the IFD0 DateTime from the block. See GetDateTime.
+func (b Block) GetDateTime() (time.Time, error) {
+	return b.getTime(dateTimeTag)
+}
+
+// GetGPSTime returns the GPS metadata Datetime from the block. See GetGPSTime.
+func (b Block) GetGPSTime() (time.Time, error) {
+	rootIfd, err := getRootIfdFromReader(bytes.NewReader(b))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return getGPSTimeFromRootIfd(rootIfd)
+}
+
+// GetCamera returns the camera Make, Model and BodySerialNumber from the block. See GetCamera.
+func (b Block) GetCamera() (Camera, error) {
+	rootIfd, err := getRootIfdFromReader(bytes.NewReader(b))
+	if err != nil {
+		return Camera{}, err
+	}
+	return getCameraFromRootIfd(rootIfd)
+}
+
+func (b Block) getTime(tags ...dateTag) (time.Time, error) {
+	rootIfd, err := getRootIfdFromReader(bytes.NewReader(b))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return getTimeFromRootIfd(rootIfd, tags...)
+}
@@ -0,0 +1,97 @@
+package exifdata
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	exifcommon "github.com/dsoprea/go-exif/v3/common"
+	"github.com/dtrejod/goexif/internal/exifdata/exiftest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTIFFBlocks(t *testing.T) {
+	blocks := TIFFBlocks{
+		IFD0: exiftest.EncodeBlock(t, exifcommon.IfdStandardIfdIdentity, map[string]interface{}{
+			"Make":     "Canon",
+			"Model":    "Canon EOS R5",
+			"DateTime": "2024:01:02 03:04:05",
+		}),
+		Exif: exiftest.EncodeBlock(t, exifcommon.IfdExifStandardIfdIdentity, map[string]interface{}{
+			"DateTimeOriginal":   "2023:10:01 12:00:00",
+			"OffsetTimeOriginal": "+09:00",
+			"BodySerialNumber":   "012345678901",
+		}),
+	}
+
+	actual, err := blocks.GetDateTimeOriginal()
+	require.NoError(t, err)
+	assert.True(t, time.Date(2023, 10, 1, 3, 0, 0, 0, time.UTC).Equal(actual), actual)
+
+	actual, err = blocks.GetDateTime()
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, UnknownLocation), actual)
+
+	_, err = blocks.GetDateTimeDigitized()
+	assert.Error(t, err)
+
+	cam, err := blocks.GetCamera()
+	require.NoError(t, err)
+	assert.Equal(t, Camera{Make: "Canon", Model: "Canon EOS R5", BodySerialNumber: "012345678901"}, cam)
+
+	_, err = blocks.GetGPSTime()
+	assert.Error(t, err)
+
+	blocks.GPS = exiftest.EncodeBlock(t, exifcommon.IfdGpsInfoStandardIfdIdentity, map[string]interface{}{
+		"GPSLatitudeRef": "N",
+		"GPSLatitude": []exifcommon.Rational{
+			{Numerator: 35, Denominator: 1},
+			{Numerator: 40, Denominator: 1},
+			{Numerator: 0, Denominator: 1},
+		},
+		"GPSLongitudeRef": "E",
+		"GPSLongitude": []exifcommon.Rational{
+			{Numerator: 139, Denominator: 1},
+			{Numerator: 39, Denominator: 1},
+			{Numerator: 0, Denominator: 1},
+		},
+		"GPSDateStamp": "2023:10:01",
+		"GPSTimeStamp": []exifcommon.Rational{
+			{Numerator: 3, Denominator: 1},
+			{Numerator: 0, Denominator: 1},
+			{Numerator: 0, Denominator: 1},
+		},
+	})
+	actual, err = blocks.GetGPSTime()
+	require.NoError(t, err)
+	assert.True(t, time.Date(2023, 10, 1, 3, 0, 0, 0, time.UTC).Equal(actual), actual)
+	assert.Equal(t, "Asia/Tokyo", actual.Location().String())
+
+	_, err = TIFFBlocks{}.GetTime()
+	assert.Error(t, err)
+}
+
+func TestBlock(t *testing.T) {
+	data, err := os.ReadFile(writeExif(t,
+		exifTag{ifdPath: "IFD", name: "Make", value: "Google"},
+		exifTag{ifdPath: "IFD/Exif", name: "DateTimeOriginal", value: "2023:10:01 12:00:00"},
+	))
+	require.NoError(t, err)
+	// the identifier used by the APP1 segment of JPEG files may precede the TIFF header
+	block := Block(append([]byte("Exif\x00\x00"), data...))
+
+	actual, err := block.GetDateTimeOriginal()
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2023, 10, 1, 12, 0, 0, 0, UnknownLocation), actual)
+
+	_, err = block.GetDateTime()
+	assert.Error(t, err)
+
+	cam, err := block.GetCamera()
+	require.NoError(t, err)
+	assert.Equal(t, Camera{Make: "Google"}, cam)
+
+	_, err = Block(nil).GetTime()
+	assert.Error(t, err)
+}
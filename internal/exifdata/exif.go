@@ -2,63 +2,359 @@ package exifdata
 
 import (
 	"errors"
+	"io"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/dsoprea/go-exif/v3"
 	exifcommon "github.com/dsoprea/go-exif/v3/common"
+	"github.com/dtrejod/goexif/internal/geotz"
+	"github.com/dtrejod/goexif/internal/rawdata"
 )
 
 const (
 	exifDateLayout = "2006:01:02 15:04:05"
+	// gpsDateLayout is the layout of the GPSDateStamp tag
+	gpsDateLayout = "2006:01:02"
+	// exifOffsetLayout is the layout of the OffsetTime* tags (e.g. "+09:00")
+	exifOffsetLayout = "-07:00"
+	// offsetTimeTag is the generic offset tag that applies when a date tag's
+	// own offset tag is missing
+	offsetTimeTag = "OffsetTime"
 )
 
 var (
-	dateTags = []string{
-		"DateTimeOriginal",
-		"DateTimeDigitized",
-	}
+	// UnknownLocation is the location used for EXIF datetimes that have no
+	// associated offset metadata. The wall clock time is preserved as written
+	// by the camera, but the true UTC offset is unknown.
+	UnknownLocation = time.FixedZone("UNKNOWN", 0)
+
+	dateTimeOriginalTag  = dateTag{name: "DateTimeOriginal", offsetName: "OffsetTimeOriginal", subSecName: "SubSecTimeOriginal"}
+	dateTimeDigitizedTag = dateTag{name: "DateTimeDigitized", offsetName: "OffsetTimeDigitized", subSecName: "SubSecTimeDigitized"}
+	// dateTimeTag is the file modification date written to IFD0. Editing software commonly rewrites it, so it is not
+	// part of the default date tags.
+	dateTimeTag = dateTag{name: "DateTime", offsetName: "OffsetTime", subSecName: "SubSecTime", inRootIfd: true}
+
+	dateTags = []dateTag{dateTimeOriginalTag, dateTimeDigitizedTag}
 )
 
-// GetTime returns the EXIF metadata Datetime from media referenced in the provided path
+// tagFinder finds the tags of a parsed IFD by name
+type tagFinder interface {
+	FindTagWithName(tagName string) ([]*exif.IfdTagEntry, error)
+}
+
+// dateTag pairs an EXIF datetime tag with the tags that record its UTC offset and fractional seconds. The offset and
+// sub-second tags are always found in IFD/Exif.
+// Ref: https://exiftool.org/TagNames/EXIF.html
+type dateTag struct {
+	name       string
+	offsetName string
+	subSecName string
+	// inRootIfd is true when the datetime tag is found in IFD0 instead of IFD/Exif
+	inRootIfd bool
+}
+
+// GetTime returns the EXIF metadata Datetime from media referenced in the provided path. If the media records the
+// UTC offset of the datetime, then the returned time is in that offset. Otherwise the time is in UnknownLocation.
+// Fractional seconds are included with millisecond precision when recorded.
 func GetTime(path string) (time.Time, error) {
+	return getTimeWithTags(path, dateTags...)
+}
+
+// GetDateTimeOriginal returns the EXIF DateTimeOriginal from media referenced in the provided path. See GetTime.
+func GetDateTimeOriginal(path string) (time.Time, error) {
+	return getTimeWithTags(path, dateTimeOriginalTag)
+}
+
+// GetDateTimeDigitized returns the EXIF DateTimeDigitized from media referenced in the provided path. See GetTime.
+func GetDateTimeDigitized(path string) (time.Time, error) {
+	return getTimeWithTags(path, dateTimeDigitizedTag)
+}
+
+// GetDateTime returns the IFD0 DateTime from media referenced in the provided path. It is the date the file was last
+// changed, which is often the capture time for media that was never edited. See GetTime.
+func GetDateTime(path string) (time.Time, error) {
+	return getTimeWithTags(path, dateTimeTag)
+}
+
+func getTimeWithTags(path string, tags ...dateTag) (time.Time, error) {
 	// get RoofIfd
 	rootIfd, err := getRootIfd(path)
 	if err != nil {
 		return time.Time{}, err
 	}
+	return getTimeFromRootIfd(rootIfd, tags...)
+}
 
-	exifIfd, err := exif.FindIfdFromRootIfd(rootIfd, "IFD/Exif")
-	if err != nil {
-		return time.Time{}, errors.New("IFD/Exif not found")
+// GetTimeFromBytes returns the EXIF metadata Datetime from the provided data containing an EXIF block. It is used for
+// containers that embed EXIF in their own structures. See GetTime.
+func GetTimeFromBytes(data []byte) (time.Time, error) {
+	return Block(data).GetTime()
+}
+
+func getTimeFromRootIfd(rootIfd *exif.Ifd, tags ...dateTag) (time.Time, error) {
+	// IFD/Exif may be missing when only IFD0 tags are requested
+	var exifIfd tagFinder
+	if ifd, err := exif.FindIfdFromRootIfd(rootIfd, "IFD/Exif"); err == nil {
+		exifIfd = ifd
 	}
+	return getTimeFromIfds(rootIfd, exifIfd, tags...)
+}
 
-	value, err := getTimeFromTag(exifIfd)
+// getTimeFromIfds returns the time of the first date tag found in IFD0 or IFD/Exif. Either IFD may be nil.
+func getTimeFromIfds(rootIfd, exifIfd tagFinder, tags ...dateTag) (time.Time, error) {
+	tag, value, err := getTimeFromTag(rootIfd, exifIfd, tags)
 	if err != nil {
 		return time.Time{}, err
 	}
 
 	// Parse string into Time
-	// TODO: Parse timezone
-	t, err := time.Parse(exifDateLayout, value)
+	t, err := time.ParseInLocation(exifDateLayout, value, getLocation(exifIfd, tag))
 	if err != nil {
 		return time.Time{}, err
 	}
-	return t.UTC(), nil
+	return t.Add(getSubSec(exifIfd, tag)), nil
+}
+
+// GetGPSTime returns the GPS metadata Datetime from media referenced in the provided path. GPS time is recorded in UTC
+// by the receiver, so it is the true instant the media was taken regardless of the camera clock. When the media has GPS
+// coordinates, then the returned time is in the timezone found at those coordinates. Otherwise the time is in UTC.
+func GetGPSTime(path string) (time.Time, error) {
+	rootIfd, err := getRootIfd(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return getGPSTimeFromRootIfd(rootIfd)
+}
+
+func getGPSTimeFromRootIfd(rootIfd *exif.Ifd) (time.Time, error) {
+	gpsIfd, err := exif.FindIfdFromRootIfd(rootIfd, "IFD/GPSInfo")
+	if err != nil {
+		return time.Time{}, errors.New("IFD/GPSInfo not found")
+	}
+	return getGPSTimeFromIfd(gpsIfd)
+}
+
+// getGPSTimeFromIfd returns the GPS time from the tags of IFD/GPSInfo. See GetGPSTime.
+func getGPSTimeFromIfd(gpsIfd tagFinder) (time.Time, error) {
+	t, err := getGPSTimestamp(gpsIfd)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	loc, err := getGPSLocation(gpsIfd)
+	if err != nil {
+		return t, nil
+	}
+	return t.In(loc), nil
+}
+
+// Camera identifies the device that took the media
+type Camera struct {
+	Make             string
+	Model            string
+	BodySerialNumber string
+}
+
+// GetCamera returns the camera Make, Model and BodySerialNumber from the EXIF metadata of media referenced in the
+// provided path. Missing tags are left empty, but at least one tag must exist.
+func GetCamera(path string) (Camera, error) {
+	rootIfd, err := getRootIfd(path)
+	if err != nil {
+		return Camera{}, err
+	}
+	return getCameraFromRootIfd(rootIfd)
+}
+
+func getCameraFromRootIfd(rootIfd *exif.Ifd) (Camera, error) {
+	var exifIfd tagFinder
+	if ifd, err := exif.FindIfdFromRootIfd(rootIfd, "IFD/Exif"); err == nil {
+		exifIfd = ifd
+	}
+	return getCameraFromIfds(rootIfd, exifIfd)
+}
+
+// getCameraFromIfds returns the camera tags found in IFD0 and IFD/Exif. Either IFD may be nil.
+func getCameraFromIfds(rootIfd, exifIfd tagFinder) (Camera, error) {
+	var cam Camera
+	if rootIfd != nil {
+		cam.Make, _ = getTagValue(rootIfd, "Make")
+		cam.Model, _ = getTagValue(rootIfd, "Model")
+	}
+	if exifIfd != nil {
+		cam.BodySerialNumber, _ = getTagValue(exifIfd, "BodySerialNumber")
+	}
+	// DNG files record the serial number in IFD0
+	if cam.BodySerialNumber == "" && rootIfd != nil {
+		cam.BodySerialNumber, _ = getTagValue(rootIfd, "CameraSerialNumber")
+	}
+
+	if cam == (Camera{}) {
+		return Camera{}, errors.New("could not find camera tags")
+	}
+	return cam, nil
+}
+
+// IsTimezoneUnknown returns true if the provided time was parsed from EXIF metadata without any offset information
+func IsTimezoneUnknown(t time.Time) bool {
+	return t.Location() == UnknownLocation
+}
+
+// IsZeroDate returns true if the provided date value has no non-zero digit. Devices without a set clock write blank
+// or zeroed dates such as "0000:00:00 00:00:00" or "    :  :     :  :  ", which are treated as no date.
+func IsZeroDate(value string) bool {
+	return !strings.ContainsAny(value, "123456789")
+}
+
+func getTimeFromTag(rootIfd, exifIfd tagFinder, tags []dateTag) (dateTag, string, error) {
+	for _, tag := range tags {
+		ifd := exifIfd
+		if tag.inRootIfd {
+			ifd = rootIfd
+		}
+		if ifd == nil {
+			continue
+		}
+		value, err := getTagValue(ifd, tag.name)
+		if err != nil || IsZeroDate(value) {
+			continue
+		}
+		return tag, value, nil
+	}
+
+	if exifIfd == nil {
+		return dateTag{}, "", errors.New("IFD/Exif not found")
+	}
+	return dateTag{}, "", errors.New("could not find known IFD/Exif date tags")
 }
 
-func getTimeFromTag(exifIfd *exif.Ifd) (string, error) {
-	for _, tag := range dateTags {
-		results, err := exifIfd.FindTagWithName(tag)
+// getLocation returns the location described by the offset tag paired with the provided date tag. When neither it nor
+// the generic OffsetTime tag is usable, UnknownLocation is returned.
+func getLocation(exifIfd tagFinder, tag dateTag) *time.Location {
+	if exifIfd == nil {
+		return UnknownLocation
+	}
+	for _, name := range []string{tag.offsetName, offsetTimeTag} {
+		value, err := getTagValue(exifIfd, name)
 		if err != nil {
 			continue
 		}
-		if len(results) == 1 {
-			return results[0].Format()
+		offset, err := time.Parse(exifOffsetLayout, strings.TrimSpace(value))
+		if err != nil {
+			continue
 		}
+		_, seconds := offset.Zone()
+		return time.FixedZone("", seconds)
+	}
+	return UnknownLocation
+}
+
+// getSubSec returns the fractional seconds recorded in the sub-second tag paired with the provided date tag truncated to
+// millisecond precision. The tag holds the decimal digits following the seconds, so "5" is 500ms and "123456" is
+// 123ms.
+func getSubSec(exifIfd tagFinder, tag dateTag) time.Duration {
+	if exifIfd == nil {
+		return 0
+	}
+	value, err := getTagValue(exifIfd, tag.subSecName)
+	if err != nil {
+		return 0
 	}
 
-	return "", errors.New("could not find known IFD/Exif date tags")
+	digits := strings.TrimSpace(value)
+	if len(digits) > 3 {
+		digits = digits[:3]
+	}
+	ms, err := strconv.Atoi(digits)
+	if err != nil || ms < 0 {
+		return 0
+	}
+	for i := len(digits); i < 3; i++ {
+		ms *= 10
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// getGPSTimestamp returns the UTC time from the GPSDateStamp and GPSTimeStamp tags
+// Ref: https://exiftool.org/TagNames/GPS.html
+func getGPSTimestamp(gpsIfd tagFinder) (time.Time, error) {
+	dateValue, err := getTagValue(gpsIfd, "GPSDateStamp")
+	if err != nil {
+		return time.Time{}, errors.New("could not find GPSDateStamp tag")
+	}
+	date, err := time.Parse(gpsDateLayout, strings.ReplaceAll(strings.TrimSpace(dateValue), "-", ":"))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	results, err := gpsIfd.FindTagWithName("GPSTimeStamp")
+	if err != nil || len(results) != 1 {
+		return time.Time{}, errors.New("could not find GPSTimeStamp tag")
+	}
+	value, err := results[0].Value()
+	if err != nil {
+		return time.Time{}, err
+	}
+	rationals, ok := value.([]exifcommon.Rational)
+	if !ok || len(rationals) != 3 {
+		return time.Time{}, errors.New("unexpected GPSTimeStamp value")
+	}
+
+	var elapsed time.Duration
+	for i, unit := range []time.Duration{time.Hour, time.Minute, time.Second} {
+		if rationals[i].Denominator == 0 {
+			return time.Time{}, errors.New("invalid GPSTimeStamp value")
+		}
+		elapsed += time.Duration(rationals[i].Numerator) * unit / time.Duration(rationals[i].Denominator)
+	}
+	return date.Add(elapsed.Truncate(time.Millisecond)), nil
+}
+
+// getGPSLocation returns the timezone location at the GPS coordinates of the media
+func getGPSLocation(gpsIfd tagFinder) (*time.Location, error) {
+	latitude, err := getGPSDegrees(gpsIfd, "GPSLatitude", "GPSLatitudeRef")
+	if err != nil {
+		return nil, err
+	}
+	longitude, err := getGPSDegrees(gpsIfd, "GPSLongitude", "GPSLongitudeRef")
+	if err != nil {
+		return nil, err
+	}
+	return geotz.Location(latitude.Decimal(), longitude.Decimal())
+}
+
+// getGPSDegrees returns the coordinate of the named GPS tag in the hemisphere of its reference tag
+func getGPSDegrees(gpsIfd tagFinder, name, refName string) (exif.GpsDegrees, error) {
+	ref, err := getTagValue(gpsIfd, refName)
+	if err != nil {
+		return exif.GpsDegrees{}, err
+	}
+	results, err := gpsIfd.FindTagWithName(name)
+	if err != nil || len(results) != 1 {
+		return exif.GpsDegrees{}, errors.New("could not find " + name + " tag")
+	}
+	value, err := results[0].Value()
+	if err != nil {
+		return exif.GpsDegrees{}, err
+	}
+	rationals, ok := value.([]exifcommon.Rational)
+	if !ok {
+		return exif.GpsDegrees{}, errors.New("unexpected " + name + " value")
+	}
+	return exif.NewGpsDegreesFromRationals(ref, rationals)
+}
+
+func getTagValue(ifd tagFinder, name string) (string, error) {
+	results, err := ifd.FindTagWithName(name)
+	if err != nil {
+		return "", err
+	}
+	if len(results) != 1 {
+		return "", errors.New("unexpected number of tags found")
+	}
+	return results[0].Format()
 }
 
 func getRootIfd(path string) (*exif.Ifd, error) {
@@ -68,7 +364,16 @@ func getRootIfd(path string) (*exif.Ifd, error) {
 	}
 	defer f.Close()
 
-	rawExif, err := exif.SearchAndExtractExifWithReader(f)
+	// RAW formats may hide their EXIF from a plain search
+	r, err := rawdata.ExifReader(f)
+	if err != nil {
+		return nil, err
+	}
+	return getRootIfdFromReader(r)
+}
+
+func getRootIfdFromReader(r io.Reader) (*exif.Ifd, error) {
+	rawExif, err := exif.SearchAndExtractExifWithReader(r)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,264 @@
+package exifdata
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dsoprea/go-exif/v3"
+	exifcommon "github.com/dsoprea/go-exif/v3/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// exifTag is a tag written into a test EXIF blob
+type exifTag struct {
+	ifdPath string
+	name    string
+	value   interface{}
+}
+
+// writeExif writes a raw EXIF blob containing the provided tags to a file in a temporary directory
+func writeExif(t *testing.T, tags ...exifTag) string {
+	t.Helper()
+
+	im, err := exifcommon.NewIfdMappingWithStandard()
+	require.NoError(t, err)
+	ti := exif.NewTagIndex()
+
+	rootIb := exif.NewIfdBuilder(im, ti, exifcommon.IfdStandardIfdIdentity, binary.BigEndian)
+	for _, tag := range tags {
+		ib, err := exif.GetOrCreateIbFromRootIb(rootIb, tag.ifdPath)
+		require.NoError(t, err)
+		require.NoError(t, ib.AddStandardWithName(tag.name, tag.value))
+	}
+
+	data, err := exif.NewIfdByteEncoder().EncodeToExif(rootIb)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "exif.tiff")
+	require.NoError(t, os.WriteFile(path, data, 0644))
+	return path
+}
+
+func TestGetTime(t *testing.T) {
+	t.Run("with offset original", func(t *testing.T) {
+		path := writeExif(t,
+			exifTag{"IFD/Exif", "DateTimeOriginal", "2023:10:01 12:00:00"},
+			exifTag{"IFD/Exif", "OffsetTimeOriginal", "+09:00"},
+			exifTag{"IFD/Exif", "OffsetTime", "-05:00"},
+		)
+
+		actual, err := GetTime(path)
+		require.NoError(t, err)
+
+		assert.True(t, time.Date(2023, 10, 1, 3, 0, 0, 0, time.UTC).Equal(actual))
+		assert.False(t, IsTimezoneUnknown(actual))
+		_, offset := actual.Zone()
+		assert.Equal(t, 9*60*60, offset)
+	})
+
+	t.Run("with generic offset", func(t *testing.T) {
+		path := writeExif(t,
+			exifTag{"IFD/Exif", "DateTimeDigitized", "2023:10:01 12:00:00"},
+			exifTag{"IFD/Exif", "OffsetTime", "-05:00"},
+		)
+
+		actual, err := GetTime(path)
+		require.NoError(t, err)
+
+		assert.True(t, time.Date(2023, 10, 1, 17, 0, 0, 0, time.UTC).Equal(actual))
+		assert.False(t, IsTimezoneUnknown(actual))
+	})
+
+	t.Run("without offset", func(t *testing.T) {
+		path := writeExif(t,
+			exifTag{"IFD/Exif", "DateTimeOriginal", "2023:10:01 12:00:00"},
+			exifTag{"IFD/Exif", "OffsetTimeOriginal", "   :  "},
+		)
+
+		actual, err := GetTime(path)
+		require.NoError(t, err)
+
+		assert.Equal(t, time.Date(2023, 10, 1, 12, 0, 0, 0, UnknownLocation), actual)
+		assert.True(t, IsTimezoneUnknown(actual))
+	})
+
+	t.Run("with sub-second", func(t *testing.T) {
+		for subSec, expected := range map[string]time.Duration{
+			"5":      500 * time.Millisecond,
+			"12":     120 * time.Millisecond,
+			"123":    123 * time.Millisecond,
+			"123456": 123 * time.Millisecond,
+			"":       0,
+			"abc":    0,
+		} {
+			path := writeExif(t,
+				exifTag{"IFD/Exif", "DateTimeOriginal", "2023:10:01 12:00:00"},
+				exifTag{"IFD/Exif", "SubSecTimeOriginal", subSec},
+				exifTag{"IFD/Exif", "SubSecTimeDigitized", "999"},
+			)
+
+			actual, err := GetTime(path)
+			require.NoError(t, err)
+			assert.Equal(t, time.Date(2023, 10, 1, 12, 0, 0, 0, UnknownLocation).Add(expected), actual, subSec)
+		}
+	})
+}
+
+func TestGetTimeByTag(t *testing.T) {
+	path := writeExif(t,
+		exifTag{"IFD", "DateTime", "2024:01:02 03:04:05"},
+		exifTag{"IFD/Exif", "DateTimeOriginal", "2023:10:01 12:00:00"},
+		exifTag{"IFD/Exif", "DateTimeDigitized", "2023:10:02 12:00:00"},
+		exifTag{"IFD/Exif", "OffsetTime", "+01:00"},
+		exifTag{"IFD/Exif", "SubSecTime", "5"},
+	)
+
+	for name, tc := range map[string]struct {
+		getTime  func(string) (time.Time, error)
+		expected time.Time
+	}{
+		"original":  {GetDateTimeOriginal, time.Date(2023, 10, 1, 11, 0, 0, 0, time.UTC)},
+		"digitized": {GetDateTimeDigitized, time.Date(2023, 10, 2, 11, 0, 0, 0, time.UTC)},
+		"ifd0":      {GetDateTime, time.Date(2024, 1, 2, 2, 4, 5, 500*int(time.Millisecond), time.UTC)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			actual, err := tc.getTime(path)
+			require.NoError(t, err)
+			assert.True(t, tc.expected.Equal(actual), actual)
+		})
+	}
+
+	t.Run("zeroed dates are skipped", func(t *testing.T) {
+		for _, zero := range []string{"0000:00:00 00:00:00", "    :  :     :  :  "} {
+			path := writeExif(t,
+				exifTag{"IFD/Exif", "DateTimeOriginal", zero},
+				exifTag{"IFD/Exif", "DateTimeDigitized", "2023:10:02 12:00:00"},
+			)
+
+			actual, err := GetTime(path)
+			require.NoError(t, err, zero)
+			assert.Equal(t, time.Date(2023, 10, 2, 12, 0, 0, 0, UnknownLocation), actual, zero)
+
+			_, err = GetDateTimeOriginal(path)
+			assert.Error(t, err, zero)
+		}
+	})
+
+	t.Run("ifd0 without exif ifd", func(t *testing.T) {
+		path := writeExif(t, exifTag{"IFD", "DateTime", "2024:01:02 03:04:05"})
+
+		actual, err := GetDateTime(path)
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, UnknownLocation), actual)
+
+		_, err = GetTime(path)
+		assert.Error(t, err)
+	})
+}
+
+func TestGetTimeRaw(t *testing.T) {
+	tiff, err := os.ReadFile(writeExif(t, exifTag{"IFD/Exif", "DateTimeOriginal", "2023:10:01 12:00:00"}))
+	require.NoError(t, err)
+	expected := time.Date(2023, 10, 1, 12, 0, 0, 0, UnknownLocation)
+
+	t.Run("orf", func(t *testing.T) {
+		orf := append([]byte("MMOR"), tiff[4:]...)
+		path := filepath.Join(t.TempDir(), "image.orf")
+		require.NoError(t, os.WriteFile(path, orf, 0644))
+
+		actual, err := GetTime(path)
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("raf", func(t *testing.T) {
+		app1 := append([]byte("Exif\x00\x00"), tiff...)
+		jpg := append([]byte{0xff, 0xd8, 0xff, 0xe1}, binary.BigEndian.AppendUint16(nil, uint16(len(app1)+2))...)
+		jpg = append(append(jpg, app1...), 0xff, 0xd9)
+
+		header := make([]byte, 100)
+		copy(header, "FUJIFILMCCD-RAW 0201FF129502X-T3")
+		binary.BigEndian.PutUint32(header[84:], uint32(len(header)))
+		binary.BigEndian.PutUint32(header[88:], uint32(len(jpg)))
+		path := filepath.Join(t.TempDir(), "image.raf")
+		require.NoError(t, os.WriteFile(path, append(header, jpg...), 0644))
+
+		actual, err := GetTime(path)
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	})
+}
+
+func TestGetCamera(t *testing.T) {
+	path := writeExif(t,
+		exifTag{"IFD", "Make", "Canon"},
+		exifTag{"IFD", "Model", "Canon EOS 5D Mark IV"},
+		exifTag{"IFD/Exif", "BodySerialNumber", "012345678901"},
+	)
+
+	actual, err := GetCamera(path)
+	require.NoError(t, err)
+	assert.Equal(t, Camera{Make: "Canon", Model: "Canon EOS 5D Mark IV", BodySerialNumber: "012345678901"}, actual)
+
+	_, err = GetCamera(writeExif(t, exifTag{"IFD/Exif", "DateTimeOriginal", "2023:10:01 12:00:00"}))
+	assert.Error(t, err)
+}
+
+func TestGetGPSTime(t *testing.T) {
+	gpsTimestamp := []exifcommon.Rational{
+		{Numerator: 23, Denominator: 1},
+		{Numerator: 30, Denominator: 1},
+		{Numerator: 1525, Denominator: 100},
+	}
+
+	t.Run("with coordinates", func(t *testing.T) {
+		path := writeExif(t,
+			exifTag{"IFD/GPSInfo", "GPSVersionID", []byte{2, 2, 0, 0}},
+			exifTag{"IFD/GPSInfo", "GPSLatitudeRef", "N"},
+			exifTag{"IFD/GPSInfo", "GPSLatitude", []exifcommon.Rational{
+				{Numerator: 35, Denominator: 1},
+				{Numerator: 40, Denominator: 1},
+				{Numerator: 0, Denominator: 1},
+			}},
+			exifTag{"IFD/GPSInfo", "GPSLongitudeRef", "E"},
+			exifTag{"IFD/GPSInfo", "GPSLongitude", []exifcommon.Rational{
+				{Numerator: 139, Denominator: 1},
+				{Numerator: 39, Denominator: 1},
+				{Numerator: 0, Denominator: 1},
+			}},
+			exifTag{"IFD/GPSInfo", "GPSDateStamp", "2023:10:01"},
+			exifTag{"IFD/GPSInfo", "GPSTimeStamp", gpsTimestamp},
+		)
+
+		actual, err := GetGPSTime(path)
+		require.NoError(t, err)
+
+		expected := time.Date(2023, 10, 1, 23, 30, 15, int(250*time.Millisecond), time.UTC)
+		assert.True(t, expected.Equal(actual))
+		assert.Equal(t, "Asia/Tokyo", actual.Location().String())
+		// local day is the day after the UTC day in Tokyo
+		assert.Equal(t, 2, actual.Day())
+	})
+
+	t.Run("without coordinates", func(t *testing.T) {
+		path := writeExif(t,
+			exifTag{"IFD/GPSInfo", "GPSDateStamp", "2023:10:01"},
+			exifTag{"IFD/GPSInfo", "GPSTimeStamp", gpsTimestamp},
+		)
+
+		actual, err := GetGPSTime(path)
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2023, 10, 1, 23, 30, 15, int(250*time.Millisecond), time.UTC), actual)
+	})
+
+	t.Run("without gps", func(t *testing.T) {
+		path := writeExif(t, exifTag{"IFD/Exif", "DateTimeOriginal", "2023:10:01 12:00:00"})
+
+		_, err := GetGPSTime(path)
+		assert.Error(t, err)
+	})
+}
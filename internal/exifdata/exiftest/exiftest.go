@@ -0,0 +1,26 @@
+// Package exiftest builds EXIF metadata for tests
+package exiftest
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/dsoprea/go-exif/v3"
+	exifcommon "github.com/dsoprea/go-exif/v3/common"
+	"github.com/stretchr/testify/require"
+)
+
+// EncodeBlock returns a TIFF block holding the provided tags in an IFD with the provided identity
+func EncodeBlock(t testing.TB, ii *exifcommon.IfdIdentity, tags map[string]interface{}) []byte {
+	t.Helper()
+
+	im, err := exifcommon.NewIfdMappingWithStandard()
+	require.NoError(t, err)
+	ib := exif.NewIfdBuilder(im, exif.NewTagIndex(), ii, binary.LittleEndian)
+	for name, value := range tags {
+		require.NoError(t, ib.AddStandardWithName(name, value))
+	}
+	data, err := exif.NewIfdByteEncoder().EncodeToExif(ib)
+	require.NoError(t, err)
+	return data
+}
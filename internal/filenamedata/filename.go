@@ -0,0 +1,132 @@
+package filenamedata
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dtrejod/goexif/internal/exifdata"
+)
+
+const (
+	yearGroup   = "year"
+	monthGroup  = "month"
+	dayGroup    = "day"
+	hourGroup   = "hour"
+	minuteGroup = "minute"
+	secondGroup = "second"
+	// msGroup is the fractional seconds following the seconds (e.g. "123")
+	msGroup = "ms"
+	// ampmGroup is the 12-hour clock period (e.g. "AM" or "PM")
+	ampmGroup = "ampm"
+)
+
+var (
+	// DefaultPatterns are the built-in filename patterns in order of
+	// preference. Each pattern uses named capture groups for the date parts;
+	// year, month and day are required while the time parts are optional.
+	DefaultPatterns = []*regexp.Regexp{
+		// Android and Pixel camera: IMG_20200626_231926.jpg, PXL_20231001_120000123.mp4,
+		// Samsung: 20200626_231926.jpg, Screenshot_20210304-101112.png
+		regexp.MustCompile(`(?:^|[^\d])(?P<year>(?:19|20)\d{2})(?P<month>\d{2})(?P<day>\d{2})[_-](?P<hour>\d{2})(?P<minute>\d{2})(?P<second>\d{2})(?P<ms>\d{3})?(?:[^\d]|$)`),
+		// macOS screenshots: Screenshot 2021-03-04 at 10.11.12.png, Screen Shot 2021-03-04 at 10.11.12 AM.png
+		regexp.MustCompile(`(?P<year>(?:19|20)\d{2})-(?P<month>\d{2})-(?P<day>\d{2}) at (?P<hour>\d{1,2})\.(?P<minute>\d{2})\.(?P<second>\d{2})(?:\s?(?P<ampm>[AaPp][Mm]))?`),
+		// Dropbox and iOS exports: 2021-03-04 10.11.12.jpg, PHOTO-2021-03-04-10-11-12.jpg, signal-2021-03-04-101112.jpg
+		regexp.MustCompile(`(?:^|[^\d])(?P<year>(?:19|20)\d{2})-(?P<month>\d{2})-(?P<day>\d{2})[ _T-](?P<hour>\d{2})[.:_-]?(?P<minute>\d{2})[.:_-]?(?P<second>\d{2})(?:[.,](?P<ms>\d{3}))?(?:[^\d]|$)`),
+		// WhatsApp: VID-20190101-WA0003.mp4, IMG-20190101-WA0003.jpg
+		regexp.MustCompile(`(?:IMG|VID|AUD|PTT|STK)-(?P<year>(?:19|20)\d{2})(?P<month>\d{2})(?P<day>\d{2})-WA\d+`),
+		// Date only: 2021-03-04.jpg, 2021_03_04_party.jpg, 20210304.jpg
+		regexp.MustCompile(`(?:^|[^\d])(?P<year>(?:19|20)\d{2})[-_]?(?P<month>\d{2})[-_]?(?P<day>\d{2})(?:[^\d]|$)`),
+	}
+
+	errNoMatch = errors.New("filename did not match any known date patterns")
+)
+
+// Parser infers the date media was taken from its filename
+type Parser struct {
+	patterns []*regexp.Regexp
+}
+
+// NewParser returns a Parser using the provided custom regex patterns followed by the DefaultPatterns. Custom patterns
+// must use named capture groups: year, month and day are required while hour, minute, second, ms and ampm are
+// optional.
+func NewParser(customPatterns []string) (*Parser, error) {
+	patterns := make([]*regexp.Regexp, 0, len(customPatterns)+len(DefaultPatterns))
+	for _, p := range customPatterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, err
+		}
+		for _, group := range []string{yearGroup, monthGroup, dayGroup} {
+			if re.SubexpIndex(group) < 0 {
+				return nil, fmt.Errorf("filename pattern %q is missing required named capture group %q", p, group)
+			}
+		}
+		patterns = append(patterns, re)
+	}
+	return &Parser{patterns: append(patterns, DefaultPatterns...)}, nil
+}
+
+// GetTime returns the datetime found in the filename of the provided path using the DefaultPatterns
+func GetTime(path string) (time.Time, error) {
+	return (&Parser{patterns: DefaultPatterns}).GetTime(path)
+}
+
+// GetTime returns the datetime found in the filename of the provided path. Filenames carry no timezone, so the
+// returned time is in exifdata.UnknownLocation.
+func (p *Parser) GetTime(path string) (time.Time, error) {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	for _, re := range p.patterns {
+		match := re.FindStringSubmatch(name)
+		if match == nil {
+			continue
+		}
+		if t, err := timeFromMatch(re, match); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errNoMatch
+}
+
+func timeFromMatch(re *regexp.Regexp, match []string) (time.Time, error) {
+	group := func(name string) (int, error) {
+		i := re.SubexpIndex(name)
+		if i < 0 || match[i] == "" {
+			return 0, nil
+		}
+		return strconv.Atoi(match[i])
+	}
+
+	var parts [7]int
+	for i, name := range []string{yearGroup, monthGroup, dayGroup, hourGroup, minuteGroup, secondGroup, msGroup} {
+		v, err := group(name)
+		if err != nil {
+			return time.Time{}, err
+		}
+		parts[i] = v
+	}
+	year, month, day, hour, minute, second, ms := parts[0], parts[1], parts[2], parts[3], parts[4], parts[5], parts[6]
+
+	if i := re.SubexpIndex(ampmGroup); i >= 0 && match[i] != "" {
+		if hour < 1 || hour > 12 {
+			return time.Time{}, errors.New("invalid 12-hour clock hour")
+		}
+		hour %= 12
+		if strings.EqualFold(match[i], "pm") {
+			hour += 12
+		}
+	}
+
+	t := time.Date(year, time.Month(month), day, hour, minute, second, ms*int(time.Millisecond), exifdata.UnknownLocation)
+	// time.Date normalizes out of range values, so a changed value means the
+	// filename contained something that only looks like a date
+	if t.Year() != year || int(t.Month()) != month || t.Day() != day ||
+		t.Hour() != hour || t.Minute() != minute || t.Second() != second || ms > 999 {
+		return time.Time{}, errors.New("invalid date in filename")
+	}
+	return t, nil
+}
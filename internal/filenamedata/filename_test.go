@@ -0,0 +1,78 @@
+package filenamedata
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dtrejod/goexif/internal/exifdata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTime(t *testing.T) {
+	date := func(year, month, day, hour, minute, second, ms int) time.Time {
+		return time.Date(year, time.Month(month), day, hour, minute, second, ms*int(time.Millisecond), exifdata.UnknownLocation)
+	}
+
+	for path, expected := range map[string]time.Time{
+		"IMG_20200626_231926.jpg":                     date(2020, 6, 26, 23, 19, 26, 0),
+		"/photos/VID_20200626_231926.mp4":             date(2020, 6, 26, 23, 19, 26, 0),
+		"PXL_20231001_120000123.mp4":                  date(2023, 10, 1, 12, 0, 0, 123),
+		"PXL_20231001_120000123.MP.jpg":               date(2023, 10, 1, 12, 0, 0, 123),
+		"20200626_231926.jpg":                         date(2020, 6, 26, 23, 19, 26, 0),
+		"Screenshot_20210304-101112.png":              date(2021, 3, 4, 10, 11, 12, 0),
+		"Screenshot 2021-03-04 at 10.11.12.png":       date(2021, 3, 4, 10, 11, 12, 0),
+		"Screen Shot 2021-03-04 at 1.11.12 PM.png":    date(2021, 3, 4, 13, 11, 12, 0),
+		"Screen Shot 2021-03-04 at 12.11.12 AM.png":   date(2021, 3, 4, 0, 11, 12, 0),
+		"2021-03-04 10.11.12.jpg":                     date(2021, 3, 4, 10, 11, 12, 0),
+		"PHOTO-2021-03-04-10-11-12.jpg":               date(2021, 3, 4, 10, 11, 12, 0),
+		"signal-2021-03-04-101112.jpg":                date(2021, 3, 4, 10, 11, 12, 0),
+		"VID-20190101-WA0003.mp4":                     date(2019, 1, 1, 0, 0, 0, 0),
+		"IMG-20190101-WA0003.jpg":                     date(2019, 1, 1, 0, 0, 0, 0),
+		"2021_03_04_party.jpg":                        date(2021, 3, 4, 0, 0, 0, 0),
+		"IMG_20200230_231926.jpg is not a valid date": {},
+	} {
+		t.Run(path, func(t *testing.T) {
+			actual, err := GetTime(path)
+			if expected.IsZero() {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, expected, actual)
+		})
+	}
+
+	for _, path := range []string{"IMG_1234.jpg", "DSC01234.JPG", "holiday.png", "IMG_20201326_231926.jpg"} {
+		t.Run(path, func(t *testing.T) {
+			_, err := GetTime(path)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestNewParser(t *testing.T) {
+	t.Run("custom pattern is preferred", func(t *testing.T) {
+		p, err := NewParser([]string{`^scan_(?P<day>\d{2})(?P<month>\d{2})(?P<year>\d{4})`})
+		require.NoError(t, err)
+
+		actual, err := p.GetTime("scan_04032021_20200626.jpg")
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2021, 3, 4, 0, 0, 0, 0, exifdata.UnknownLocation), actual)
+
+		// built-in patterns still apply
+		actual, err = p.GetTime("IMG_20200626_231926.jpg")
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2020, 6, 26, 23, 19, 26, 0, exifdata.UnknownLocation), actual)
+	})
+
+	t.Run("missing required group", func(t *testing.T) {
+		_, err := NewParser([]string{`(?P<year>\d{4})(?P<month>\d{2})`})
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid regex", func(t *testing.T) {
+		_, err := NewParser([]string{`(`})
+		assert.Error(t, err)
+	})
+}
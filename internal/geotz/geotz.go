@@ -0,0 +1,45 @@
+package geotz
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+	// tzdata import for side effect of embedding the IANA timezone database so
+	// lookups work on hosts without zoneinfo installed
+	_ "time/tzdata"
+
+	"github.com/ringsaturn/tzf"
+)
+
+var (
+	// finder is lazily initialized since loading the embedded timezone
+	// boundaries is expensive and only needed when media has GPS metadata
+	finder     tzf.F
+	finderErr  error
+	finderOnce sync.Once
+
+	errNoTimezone = errors.New("no timezone found for coordinates")
+)
+
+// Location returns the timezone location for the provided coordinates. The
+// lookup uses timezone boundary data embedded in the binary so it works
+// without network access.
+func Location(lat, lng float64) (*time.Location, error) {
+	if lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		return nil, fmt.Errorf("invalid coordinates lat=%f lng=%f", lat, lng)
+	}
+
+	finderOnce.Do(func() {
+		finder, finderErr = tzf.NewDefaultFinder()
+	})
+	if finderErr != nil {
+		return nil, finderErr
+	}
+
+	name := finder.GetTimezoneName(lng, lat)
+	if name == "" {
+		return nil, errNoTimezone
+	}
+	return time.LoadLocation(name)
+}
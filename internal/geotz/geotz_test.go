@@ -0,0 +1,31 @@
+package geotz
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocation(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		lat, lng float64
+		expected string
+	}{
+		{"tokyo", 35.6762, 139.6503, "Asia/Tokyo"},
+		{"new york", 40.7128, -74.0060, "America/New_York"},
+		{"paris", 48.8566, 2.3522, "Europe/Paris"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			loc, err := Location(tc.lat, tc.lng)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, loc.String())
+		})
+	}
+
+	t.Run("invalid coordinates", func(t *testing.T) {
+		_, err := Location(91, 0)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,195 @@
+package iclouddata
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dtrejod/goexif/internal/dircache"
+)
+
+const (
+	// detailsPattern matches the CSV files an iCloud Photos export lists its media in. Large exports are split into
+	// Photo Details.csv, Photo Details-1.csv, etc.
+	detailsPattern = "Photo Details*.csv"
+
+	nameColumn         = "imgName"
+	creationDateColumn = "originalCreationDate"
+
+	// maxCachedDirs is the number of directories whose details are kept
+	maxCachedDirs = 16
+)
+
+var (
+	// creationDateLayouts are the layouts of the originalCreationDate column without its trailing timezone, e.g.
+	// "Saturday June 27,2020 6:19 AM" of "Saturday June 27,2020 6:19 AM GMT"
+	creationDateLayouts = []string{
+		"Monday January 2,2006 3:04 PM",
+		"Monday January 2, 2006 3:04 PM",
+		"Monday January 2,2006 3:04:05 PM",
+	}
+	// zoneOffsets are the UTC offsets of the timezone abbreviations exports are written in. Exports use GMT, unless
+	// the exporting device was set to a local timezone. Ambiguous abbreviations, such as IST, are not known.
+	zoneOffsets = map[string]time.Duration{
+		"GMT":  0,
+		"UTC":  0,
+		"BST":  1 * time.Hour,
+		"CET":  1 * time.Hour,
+		"CEST": 2 * time.Hour,
+		"EET":  2 * time.Hour,
+		"EEST": 3 * time.Hour,
+		"JST":  9 * time.Hour,
+		"AEST": 10 * time.Hour,
+		"AEDT": 11 * time.Hour,
+		"HST":  -10 * time.Hour,
+		"AKST": -9 * time.Hour,
+		"AKDT": -8 * time.Hour,
+		"PST":  -8 * time.Hour,
+		"PDT":  -7 * time.Hour,
+		"MST":  -7 * time.Hour,
+		"MDT":  -6 * time.Hour,
+		"CST":  -6 * time.Hour,
+		"CDT":  -5 * time.Hour,
+		"EST":  -5 * time.Hour,
+		"EDT":  -4 * time.Hour,
+	}
+	// gmtOffset matches timezones written as an offset from GMT, e.g. GMT+2 or GMT-07:00
+	gmtOffset = regexp.MustCompile(`^(?:GMT|UTC)([+-])(\d{1,2})(?::?(\d{2}))?$`)
+
+	// cache holds the parsed details of each directory since every media file in an export directory shares them
+	cache = dircache.New(maxCachedDirs, readDetails)
+
+	errNoDetails = errors.New("could not find media in iCloud Photo Details")
+)
+
+// details maps media filenames to their originalCreationDate
+type details map[string]string
+
+// GetTime returns the originalCreationDate from the iCloud "Photo Details.csv" in the directory of the media referenced
+// in the provided path. The returned time is in UTC.
+func GetTime(path string) (time.Time, error) {
+	dir, name := filepath.Split(path)
+	d, err := cache.Get(filepath.Clean(dir))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	value, ok := d[name]
+	if !ok {
+		return time.Time{}, errNoDetails
+	}
+	return parseDate(value)
+}
+
+// readDetails returns the details from all Photo Details CSV files in the provided directory. A directory without any
+// has empty details.
+func readDetails(dir string) (details, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, detailsPattern))
+	if err != nil {
+		return nil, err
+	}
+
+	d := make(details)
+	for _, p := range paths {
+		f, err := os.Open(p)
+		if err != nil {
+			return nil, err
+		}
+		err = readDetailsFromReader(f, d)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+	}
+	return d, nil
+}
+
+func readDetailsFromReader(r io.Reader, d details) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err != nil {
+		return err
+	}
+	nameIdx, dateIdx := -1, -1
+	for i, column := range header {
+		// exports may start with a UTF-8 byte order mark
+		switch strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")) {
+		case nameColumn:
+			nameIdx = i
+		case creationDateColumn:
+			dateIdx = i
+		}
+	}
+	if nameIdx < 0 || dateIdx < 0 {
+		return fmt.Errorf("missing %s or %s column", nameColumn, creationDateColumn)
+	}
+
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if nameIdx >= len(record) || dateIdx >= len(record) {
+			continue
+		}
+		d[record[nameIdx]] = record[dateIdx]
+	}
+}
+
+// parseDate parses the originalCreationDate. The timezone abbreviation is looked up explicitly since time.Parse treats
+// abbreviations unknown to the local timezone as UTC.
+func parseDate(value string) (time.Time, error) {
+	value = strings.Join(strings.Fields(value), " ")
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+
+	i := strings.LastIndexByte(value, ' ')
+	if i < 0 {
+		return time.Time{}, fmt.Errorf("unknown iCloud date format %q", value)
+	}
+	loc, err := zoneLocation(value[i+1:])
+	if err != nil {
+		return time.Time{}, err
+	}
+	for _, layout := range creationDateLayouts {
+		if t, err := time.ParseInLocation(layout, value[:i], loc); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unknown iCloud date format %q", value)
+}
+
+// zoneLocation returns the location of a timezone abbreviation or GMT offset
+func zoneLocation(zone string) (*time.Location, error) {
+	if offset, ok := zoneOffsets[strings.ToUpper(zone)]; ok {
+		return time.FixedZone(zone, int(offset.Seconds())), nil
+	}
+
+	m := gmtOffset.FindStringSubmatch(strings.ToUpper(zone))
+	if m == nil {
+		return nil, fmt.Errorf("unknown iCloud timezone %q", zone)
+	}
+	hours, _ := strconv.Atoi(m[2])
+	minutes, _ := strconv.Atoi(m[3])
+	if hours > 14 || minutes > 59 {
+		return nil, fmt.Errorf("invalid iCloud timezone offset %q", zone)
+	}
+	offset := hours*60*60 + minutes*60
+	if m[1] == "-" {
+		offset = -offset
+	}
+	return time.FixedZone(zone, offset), nil
+}
@@ -0,0 +1,70 @@
+package iclouddata
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTime(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Photo Details.csv"), []byte("\ufeff"+
+		"imgName,fileChecksum,favorite,hidden,deleted,originalCreationDate,viewCount,importDate\n"+
+		"IMG_1234.HEIC,abc=,no,no,no,\"Friday June 26,2020 11:19 PM GMT\",1,\"Saturday June 27,2020 6:19 AM GMT\"\n"+
+		"IMG_5678.MOV,def=,no,no,no,not a date,0,\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Photo Details-1.csv"), []byte(
+		"imgName,originalCreationDate\n"+
+			"IMG_9999.JPG,\"Monday August 5, 2019 3:42 PM GMT\"\n"), 0644))
+
+	for name, expected := range map[string]time.Time{
+		"IMG_1234.HEIC": time.Date(2020, 6, 26, 23, 19, 0, 0, time.UTC),
+		"IMG_9999.JPG":  time.Date(2019, 8, 5, 15, 42, 0, 0, time.UTC),
+	} {
+		t.Run(name, func(t *testing.T) {
+			actual, err := GetTime(filepath.Join(dir, name))
+			require.NoError(t, err)
+			assert.Equal(t, expected, actual)
+		})
+	}
+
+	for _, name := range []string{"IMG_5678.MOV", "IMG_0000.JPG"} {
+		t.Run(name, func(t *testing.T) {
+			_, err := GetTime(filepath.Join(dir, name))
+			assert.Error(t, err)
+		})
+	}
+
+	t.Run("without details", func(t *testing.T) {
+		_, err := GetTime(filepath.Join(t.TempDir(), "IMG_1234.HEIC"))
+		assert.Error(t, err)
+	})
+}
+
+func TestParseDate(t *testing.T) {
+	for value, expected := range map[string]time.Time{
+		"Friday June 26,2020 11:19 PM GMT":      time.Date(2020, 6, 26, 23, 19, 0, 0, time.UTC),
+		"Friday June 26,2020 4:19 PM PDT":       time.Date(2020, 6, 26, 23, 19, 0, 0, time.UTC),
+		"Saturday June 27,2020 1:19 AM CEST":    time.Date(2020, 6, 26, 23, 19, 0, 0, time.UTC),
+		"Saturday June 27,2020 1:19 AM GMT+2":   time.Date(2020, 6, 26, 23, 19, 0, 0, time.UTC),
+		"Friday June 26,2020 4:19 PM GMT-07:00": time.Date(2020, 6, 26, 23, 19, 0, 0, time.UTC),
+		"Friday June 26,2020 11:19:26 PM UTC":   time.Date(2020, 6, 26, 23, 19, 26, 0, time.UTC),
+		"2020-06-26T16:19:26-07:00":             time.Date(2020, 6, 26, 23, 19, 26, 0, time.UTC),
+	} {
+		t.Run(value, func(t *testing.T) {
+			actual, err := parseDate(value)
+			require.NoError(t, err)
+			assert.Equal(t, expected, actual)
+		})
+	}
+
+	for _, value := range []string{"Friday June 26,2020 4:19 PM XYZ", "Friday June 26,2020 4:19 PM GMT+25", "not a date"} {
+		t.Run(value, func(t *testing.T) {
+			_, err := parseDate(value)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package id3data
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/dtrejod/goexif/internal/exifdata"
+)
+
+const (
+	// headerSize is the size of the ID3v2 tag header and of each ID3v2.3 and ID3v2.4 frame header
+	// Ref: https://id3.org/id3v2.4.0-structure
+	headerSize = 10
+	// maxTagSize bounds the size of a tag that is read into memory. Tags may embed cover art, so they can be large.
+	maxTagSize = 16 << 20
+
+	// unsynchronisationFlag and extendedHeaderFlag are tag header flags
+	unsynchronisationFlag = 0x80
+	extendedHeaderFlag    = 0x40
+
+	// text encodings of text frames
+	encodingISO88591 = 0
+	encodingUTF16    = 1
+	encodingUTF16BE  = 2
+	encodingUTF8     = 3
+)
+
+var (
+	// id3Magic starts every ID3v2 tag
+	id3Magic = []byte("ID3")
+
+	// recordingTimeFrame is the ID3v2.4 recording time. ID3v2.3 splits the recording time into the year, date and time
+	// frames.
+	recordingTimeFrame = "TDRC"
+	yearFrame          = "TYER"
+	dateFrame          = "TDAT"
+	timeFrame          = "TIME"
+
+	// recordingTimeLayouts are the layouts of the ID3v2.4 timestamp precise to at least a day. Music is commonly tagged
+	// with just a release year, which is not a recording date.
+	recordingTimeLayouts = []string{
+		"2006-01-02T15:04:05",
+		"2006-01-02T15:04",
+		"2006-01-02T15",
+		"2006-01-02",
+	}
+)
+
+// GetTime returns the recording time from the ID3v2 tag of the MP3 media referenced in the provided path. ID3v2.4 tags
+// store it in the TDRC frame and ID3v2.3 tags in the TYER, TDAT and TIME frames. The time is local time of an unknown
+// timezone, so it is in exifdata.UnknownLocation.
+func GetTime(path string) (time.Time, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer f.Close()
+
+	frames, err := readFrames(f)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return getTimeFromFrames(frames)
+}
+
+// readFrames returns the text of the date frames of the ID3v2 tag at the start of r
+func readFrames(r io.Reader) (map[string]string, error) {
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(header, id3Magic) {
+		return nil, errors.New("could not find ID3v2 tag")
+	}
+	version, flags := header[3], header[5]
+	if version != 3 && version != 4 {
+		return nil, fmt.Errorf("unsupported ID3v2 version: 2.%d", version)
+	}
+	size := syncsafe(header[6:])
+	if size > maxTagSize {
+		return nil, fmt.Errorf("ID3v2 tag too large: %d bytes", size)
+	}
+
+	tag := make([]byte, size)
+	if _, err := io.ReadFull(r, tag); err != nil {
+		return nil, err
+	}
+	if flags&unsynchronisationFlag != 0 {
+		tag = bytes.ReplaceAll(tag, []byte{0xff, 0x00}, []byte{0xff})
+	}
+	if flags&extendedHeaderFlag != 0 && len(tag) >= 4 {
+		// the ID3v2.3 extended header size excludes the size itself
+		extSize := int(binary.BigEndian.Uint32(tag)) + 4
+		if version == 4 {
+			extSize = int(syncsafe(tag))
+		}
+		if extSize > len(tag) {
+			return nil, errors.New("invalid ID3v2 extended header")
+		}
+		tag = tag[extSize:]
+	}
+
+	frames := make(map[string]string)
+	for len(tag) >= headerSize && tag[0] != 0 {
+		id := string(tag[:4])
+		frameSize := int(binary.BigEndian.Uint32(tag[4:]))
+		if version == 4 {
+			frameSize = int(syncsafe(tag[4:]))
+		}
+		if frameSize > len(tag)-headerSize {
+			return nil, fmt.Errorf("invalid ID3v2 frame size: %s", id)
+		}
+		data := tag[headerSize : headerSize+frameSize]
+		tag = tag[headerSize+frameSize:]
+
+		switch id {
+		case recordingTimeFrame, yearFrame, dateFrame, timeFrame:
+			frames[id] = decodeText(data)
+		}
+	}
+	return frames, nil
+}
+
+func getTimeFromFrames(frames map[string]string) (time.Time, error) {
+	if value, ok := frames[recordingTimeFrame]; ok {
+		for _, layout := range recordingTimeLayouts {
+			if t, err := time.ParseInLocation(layout, value, exifdata.UnknownLocation); err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("unknown ID3v2 recording time format: %q", value)
+	}
+
+	// TDAT is DDMM and TIME is HHMM
+	year, date := frames[yearFrame], frames[dateFrame]
+	if year == "" || date == "" {
+		return time.Time{}, errors.New("could not find ID3v2 recording time")
+	}
+	if clock := frames[timeFrame]; clock != "" {
+		if t, err := time.ParseInLocation("20060201 1504", year+date+" "+clock, exifdata.UnknownLocation); err == nil {
+			return t, nil
+		}
+	}
+	t, err := time.ParseInLocation("20060201", year+date, exifdata.UnknownLocation)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unknown ID3v2 date format: %q %q", year, date)
+	}
+	return t, nil
+}
+
+// decodeText returns the first string of a text frame
+func decodeText(data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+	encoding, text := data[0], data[1:]
+
+	var s string
+	switch encoding {
+	case encodingUTF16, encodingUTF16BE:
+		var order binary.ByteOrder = binary.BigEndian
+		if encoding == encodingUTF16 && len(text) >= 2 {
+			if text[0] == 0xff && text[1] == 0xfe {
+				order = binary.LittleEndian
+			}
+			text = text[2:]
+		}
+		units := make([]uint16, 0, len(text)/2)
+		for i := 0; i+1 < len(text); i += 2 {
+			units = append(units, order.Uint16(text[i:]))
+		}
+		s = string(utf16.Decode(units))
+	case encodingISO88591:
+		runes := make([]rune, len(text))
+		for i, b := range text {
+			runes[i] = rune(b)
+		}
+		s = string(runes)
+	case encodingUTF8:
+		s = string(text)
+	}
+
+	// multiple strings are separated by null characters
+	if i := strings.IndexRune(s, 0); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s)
+}
+
+// syncsafe decodes a 4 byte integer with the most significant bit of each byte unset
+func syncsafe(b []byte) uint32 {
+	return uint32(b[0]&0x7f)<<21 | uint32(b[1]&0x7f)<<14 | uint32(b[2]&0x7f)<<7 | uint32(b[3]&0x7f)
+}
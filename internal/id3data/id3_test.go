@@ -0,0 +1,130 @@
+package id3data
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dtrejod/goexif/internal/exifdata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encodeSyncsafe(n int) []byte {
+	return []byte{byte(n >> 21 & 0x7f), byte(n >> 14 & 0x7f), byte(n >> 7 & 0x7f), byte(n & 0x7f)}
+}
+
+// frame returns an ID3v2 frame of the provided version
+func frame(version byte, id string, data []byte) []byte {
+	out := []byte(id)
+	if version == 4 {
+		out = append(out, encodeSyncsafe(len(data))...)
+	} else {
+		out = binary.BigEndian.AppendUint32(out, uint32(len(data)))
+	}
+	return append(append(out, 0, 0), data...)
+}
+
+// text returns the data of an ISO-8859-1 text frame
+func text(s string) []byte {
+	return append([]byte{encodingISO88591}, s...)
+}
+
+// writeMP3 writes an MP3 file starting with an ID3v2 tag holding the provided frames
+func writeMP3(t *testing.T, version byte, frames ...[]byte) string {
+	t.Helper()
+	var tag []byte
+	for _, f := range frames {
+		tag = append(tag, f...)
+	}
+	// padding follows the frames
+	tag = append(tag, make([]byte, 32)...)
+
+	data := append([]byte{'I', 'D', '3', version, 0, 0}, encodeSyncsafe(len(tag))...)
+	data = append(data, tag...)
+	data = append(data, 0xff, 0xfb, 0x90, 0x00)
+	path := filepath.Join(t.TempDir(), "audio.mp3")
+	require.NoError(t, os.WriteFile(path, data, 0644))
+	return path
+}
+
+func TestGetTime(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		version  byte
+		frames   [][]byte
+		expected time.Time
+		wantErr  bool
+	}{
+		{
+			name:     "v2.4 TDRC",
+			version:  4,
+			frames:   [][]byte{frame(4, "TIT2", text("Memo")), frame(4, "TDRC", text("2023-10-01T12:30:45"))},
+			expected: time.Date(2023, 10, 1, 12, 30, 45, 0, exifdata.UnknownLocation),
+		},
+		{
+			name:    "v2.4 TDRC utf-16",
+			version: 4,
+			frames: [][]byte{frame(4, "TDRC", append([]byte{encodingUTF16, 0xff, 0xfe},
+				'2', 0, '0', 0, '2', 0, '3', 0, '-', 0, '1', 0, '0', 0, '-', 0, '0', 0, '1', 0, 0, 0))},
+			expected: time.Date(2023, 10, 1, 0, 0, 0, 0, exifdata.UnknownLocation),
+		},
+		{
+			name:     "v2.4 TDRC utf-8 without seconds",
+			version:  4,
+			frames:   [][]byte{frame(4, "TDRC", append([]byte{encodingUTF8}, "2023-10-01T12:30"...))},
+			expected: time.Date(2023, 10, 1, 12, 30, 0, 0, exifdata.UnknownLocation),
+		},
+		{
+			name:    "v2.4 TDRC release year",
+			version: 4,
+			frames:  [][]byte{frame(4, "TDRC", text("1999"))},
+			wantErr: true,
+		},
+		{
+			name:    "v2.3 TYER TDAT TIME",
+			version: 3,
+			frames: [][]byte{
+				frame(3, "TYER", text("2023")), frame(3, "TDAT", text("0110")), frame(3, "TIME", text("1230")),
+			},
+			expected: time.Date(2023, 10, 1, 12, 30, 0, 0, exifdata.UnknownLocation),
+		},
+		{
+			name:     "v2.3 TYER TDAT",
+			version:  3,
+			frames:   [][]byte{frame(3, "TYER", text("2023")), frame(3, "TDAT", text("0110"))},
+			expected: time.Date(2023, 10, 1, 0, 0, 0, 0, exifdata.UnknownLocation),
+		},
+		{
+			name:    "v2.3 TYER",
+			version: 3,
+			frames:  [][]byte{frame(3, "TYER", text("2023"))},
+			wantErr: true,
+		},
+		{
+			name:    "v2.2",
+			version: 2,
+			frames:  [][]byte{[]byte("TYE\x00\x00\x05\x002023")},
+			wantErr: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := GetTime(writeMP3(t, tc.version, tc.frames...))
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+
+	t.Run("without tag", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "audio.mp3")
+		require.NoError(t, os.WriteFile(path, []byte{0xff, 0xfb, 0x90, 0x00, 0, 0, 0, 0, 0, 0, 0, 0}, 0644))
+		_, err := GetTime(path)
+		assert.Error(t, err)
+	})
+}
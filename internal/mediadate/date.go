@@ -3,20 +3,23 @@ package mediadate
 import (
 	"context"
 
+	"github.com/dtrejod/goexif/internal/clockskew"
+	"github.com/dtrejod/goexif/internal/dateresolver"
+	"github.com/dtrejod/goexif/internal/exifdata"
 	"github.com/dtrejod/goexif/internal/ilog"
 	"github.com/dtrejod/goexif/internal/mediatype"
 	"github.com/dtrejod/goexif/internal/visitors"
 	"go.uber.org/zap"
 )
 
-// Print logs the datetime for a provided mediafile
-func Print(ctx context.Context, path string, useMagicSignature bool) error {
+// Print logs the datetime for a provided mediafile. The dateresolver.DefaultSources are used when resolver is nil.
+func Print(ctx context.Context, path string, useMagicSignature bool, resolver *dateresolver.Resolver) error {
 	media, err := mediatype.NewFormat(path, useMagicSignature)
 	if err != nil {
 		return err
 	}
 
-	visitorFunc := visitors.NewMediaMetadataFilename(ctx, nil, false, false, false)
+	visitorFunc := visitors.NewMediaMetadataFilename(ctx, nil, resolver, false, false)
 	visitor := mediatype.FormatWithVisitor[visitors.MediaMetadata](media)
 	mediaMetadata, err := visitor.Accept(ctx, visitorFunc)
 	if err != nil {
@@ -26,6 +29,46 @@ func Print(ctx context.Context, path string, useMagicSignature bool) error {
 	ilog.FromContext(ctx).Info("Found date metadata for media.",
 		zap.String("sourceFile", path),
 		zap.String("humanTimestamp", mediaMetadata.Timestamp.String()),
+		zap.String("dateSource", mediaMetadata.DateSource),
 		zap.Time("unixTimestamp", mediaMetadata.Timestamp))
 	return nil
 }
+
+// ClockSkewRule returns the clock skew rule for the camera that took a reference photo of a clock. The shown time is
+// the time the clock in the photo shows, e.g. "14:05". See clockskew.ReferenceRule.
+func ClockSkewRule(ctx context.Context, path string, useMagicSignature bool, shown string) (clockskew.Rule, error) {
+	media, err := mediatype.NewFormat(path, useMagicSignature)
+	if err != nil {
+		return clockskew.Rule{}, err
+	}
+
+	// the camera date is used as-is since a skewed clock may record invalid dates
+	var sources []string
+	for _, name := range dateresolver.KnownSources {
+		if dateresolver.IsCameraClock(name) {
+			sources = append(sources, name)
+		}
+	}
+	resolver, err := dateresolver.NewFromNames(sources, nil)
+	if err != nil {
+		return clockskew.Rule{}, err
+	}
+	resolver.SetValidator(nil)
+
+	visitorFunc := visitors.NewMediaMetadataFilename(ctx, nil, resolver, false, false)
+	visitor := mediatype.FormatWithVisitor[visitors.MediaMetadata](media)
+	mediaMetadata, err := visitor.Accept(ctx, visitorFunc)
+	if err != nil {
+		return clockskew.Rule{}, err
+	}
+
+	var cam exifdata.Camera
+	if r, ok := mediatype.Lookup(media.MediaType()); ok {
+		cam = dateresolver.GetCamera(dateresolver.Media{Path: path, Dates: r.Dates})
+	}
+	if cam == (exifdata.Camera{}) {
+		ilog.FromContext(ctx).Warn("No camera metadata found. The rule applies to all cameras.",
+			zap.String("sourceFile", path))
+	}
+	return clockskew.ReferenceRule(cam, mediaMetadata.Timestamp, shown)
+}
@@ -0,0 +1,168 @@
+package mediaformats
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"image"
+	"io"
+	"os"
+
+	"image/jpeg"
+	// png import for side effect of decoding png images
+	_ "image/png"
+	// tiff import for side effect of decoding tiff images
+	_ "golang.org/x/image/tiff"
+	// webp import for side effect of decoding webp images
+	_ "golang.org/x/image/webp"
+
+	"github.com/corona10/goimagehash"
+	"github.com/dtrejod/goexif/internal/ilog"
+	"github.com/dtrejod/goexif/internal/moovdata"
+	"github.com/dtrejod/goexif/internal/rawdata"
+	"go.uber.org/zap"
+)
+
+const (
+	// pHashDistanceUpperBound is the upper bound distance between 2 image
+	// perception hashes that determines when the images are same
+	// A hash distance > 50 is a typical threshold for different images.
+	pHashDistanceUpperBound = 25
+)
+
+func compareUsingPHash(ctx context.Context, src, dest string) (bool, error) {
+	return comparePerceptionHashes(ctx, src, dest, getImagePerceptionHash)
+}
+
+// compareUsingPreviewPHash compares RAW media by the perception hash of their embedded JPEG previews, since the RAW
+// sensor data cannot be decoded
+func compareUsingPreviewPHash(ctx context.Context, src, dest string) (bool, error) {
+	return comparePerceptionHashes(ctx, src, dest, getPreviewPerceptionHash)
+}
+
+func comparePerceptionHashes(
+	ctx context.Context,
+	src, dest string,
+	hashFunc func(string) (*goimagehash.ImageHash, error),
+) (bool, error) {
+	logger := ilog.FromContext(ctx).With(
+		zap.String("sourcePath", src),
+		zap.String("destinationPath", dest))
+
+	hashA, err := hashFunc(src)
+	if err != nil {
+		return false, err
+	}
+
+	hashB, err := hashFunc(dest)
+	if err != nil {
+		return false, err
+	}
+
+	distance, err := hashA.Distance(hashB)
+	if err != nil {
+		return false, fmt.Errorf("%w: failed to compare images for duplicates", err)
+	}
+
+	logger.Debug("Comparing images...",
+		zap.String("hashA", hashA.ToString()),
+		zap.String("hashB", hashB.ToString()),
+		zap.Int("distance", distance))
+
+	return distance < pHashDistanceUpperBound, nil
+}
+
+// getImagePerceptionHash returns the peception hash of the image. The phash is
+// taken first performing a discrete cosine transform on the image. Then it
+// compares each pixel to it's average. If it is larger then output 1 else 0
+// otherwise.
+// Since a Phash operates in the frequency domain, it should be more tolerant
+// to color shifts, size scaling, watermarks, and compression between 2 images.
+func getImagePerceptionHash(path string) (*goimagehash.ImageHash, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to open image", err)
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to decode image", err)
+	}
+
+	hashA, err := goimagehash.PerceptionHash(img)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to get perception hash image", err)
+	}
+	return hashA, nil
+}
+
+// compareUsingCR3ThumbnailPHash compares Canon CR3 media by the perception hash of the JPEG thumbnail in their metadata
+func compareUsingCR3ThumbnailPHash(ctx context.Context, src, dest string) (bool, error) {
+	return comparePerceptionHashes(ctx, src, dest, getCR3PerceptionHash)
+}
+
+// getCR3PerceptionHash returns the perception hash of the JPEG thumbnail of Canon CR3 media. See
+// getImagePerceptionHash.
+func getCR3PerceptionHash(path string) (*goimagehash.ImageHash, error) {
+	md, err := moovdata.GetCR3Metadata(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to find cr3 thumbnail", err)
+	}
+	return getJPEGPerceptionHash(md.Thumbnail)
+}
+
+// getPreviewPerceptionHash returns the perception hash of the JPEG preview embedded in RAW media. See
+// getImagePerceptionHash.
+func getPreviewPerceptionHash(path string) (*goimagehash.ImageHash, error) {
+	preview, err := rawdata.GetPreview(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to find raw preview", err)
+	}
+	return getJPEGPerceptionHash(preview)
+}
+
+// getJPEGPerceptionHash returns the perception hash of a JPEG image. See getImagePerceptionHash.
+func getJPEGPerceptionHash(data []byte) (*goimagehash.ImageHash, error) {
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to decode jpeg", err)
+	}
+
+	hash, err := goimagehash.PerceptionHash(img)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to get perception hash image", err)
+	}
+	return hash, nil
+}
+
+func compareUsingSHA256(ctx context.Context, src, dest string) (bool, error) {
+	hashA, err := getSHA256Hash(src)
+	if err != nil {
+		return false, err
+	}
+
+	hashB, err := getSHA256Hash(dest)
+	if err != nil {
+		return false, err
+	}
+
+	return bytes.Equal(hashA, hashB), nil
+}
+
+// getSHA256Hash returns the sha256 hash of a file
+func getSHA256Hash(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+
+	return h.Sum(nil), nil
+}
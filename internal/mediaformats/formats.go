@@ -0,0 +1,207 @@
+package mediaformats
+
+import (
+	"bytes"
+	"sync"
+	"time"
+
+	"github.com/dtrejod/goexif/internal/avchddata"
+	"github.com/dtrejod/goexif/internal/dateresolver"
+	"github.com/dtrejod/goexif/internal/exifdata"
+	"github.com/dtrejod/goexif/internal/id3data"
+	"github.com/dtrejod/goexif/internal/mediatype"
+	"github.com/dtrejod/goexif/internal/mkvdata"
+	"github.com/dtrejod/goexif/internal/moidata"
+	"github.com/dtrejod/goexif/internal/moovdata"
+	"github.com/dtrejod/goexif/internal/rawdata"
+	"github.com/dtrejod/goexif/internal/riffdata"
+)
+
+var (
+	// jxlCodestream starts a bare JPEG XL codestream and jxlContainer starts a JPEG XL ISOBMFF-style container
+	// Ref: https://github.com/libjxl/libjxl/blob/main/doc/format_overview.md
+	jxlCodestream = []byte{0xff, 0x0a}
+	jxlContainer  = []byte{0x00, 0x00, 0x00, 0x0c, 'J', 'X', 'L', ' ', 0x0d, 0x0a, 0x87, 0x0a}
+
+	// exifDates reads media storing an EXIF block, e.g. JPEG, PNG, HEIF and TIFF images
+	exifDates = dateresolver.Extractor{
+		Dates: map[string]func(string) (time.Time, error){
+			dateresolver.ExifOriginal:  exifdata.GetDateTimeOriginal,
+			dateresolver.ExifDigitized: exifdata.GetDateTimeDigitized,
+			dateresolver.IFD0DateTime:  exifdata.GetDateTime,
+			dateresolver.GPS:           exifdata.GetGPSTime,
+		},
+		Camera: exifdata.GetCamera,
+	}
+	// quickTimeDates reads media using QuickTime/ISOBMFF boxes, e.g. MOV, MP4 and 3GP videos and M4A audio
+	quickTimeDates = dateresolver.Extractor{
+		Dates:  map[string]func(string) (time.Time, error){dateresolver.QuickTime: moovdata.GetTime},
+		Camera: moovdata.GetCamera,
+	}
+	// riffDates reads media using RIFF chunks, e.g. AVI videos and WAV audio
+	riffDates = dateresolver.Extractor{
+		Dates: map[string]func(string) (time.Time, error){dateresolver.RIFF: riffdata.GetTime},
+	}
+	// matroskaDates reads media using EBML elements, e.g. MKV and WebM videos
+	matroskaDates = dateresolver.Extractor{
+		Dates: map[string]func(string) (time.Time, error){dateresolver.Matroska: mkvdata.GetTime},
+	}
+	// avchdDates reads media using MPEG transport streams, e.g. the MTS videos of AVCHD camcorders
+	avchdDates = dateresolver.Extractor{
+		Dates: map[string]func(string) (time.Time, error){dateresolver.AVCHD: avchddata.GetTime},
+	}
+	// moiDates reads the MOI sidecar of media using MPEG program streams, e.g. the MOD and TOD videos of SD camcorders
+	moiDates = dateresolver.Extractor{
+		Dates: map[string]func(string) (time.Time, error){dateresolver.MOI: moidata.GetTime},
+	}
+	// id3Dates reads media storing an ID3v2 tag, e.g. MP3 audio
+	id3Dates = dateresolver.Extractor{
+		Dates: map[string]func(string) (time.Time, error){dateresolver.ID3: id3data.GetTime},
+	}
+
+	// duplicates are compared by their SHA-256 hash, their decoded images, or the JPEG previews embedded in RAW media
+	sha256Hash   = mediatype.Comparer{Name: "sha256", Compare: compareUsingSHA256}
+	pHash        = mediatype.Comparer{Name: "phash", Compare: compareUsingPHash}
+	previewPHash = mediatype.Comparer{Name: "preview-phash", Compare: compareUsingPreviewPHash}
+
+	// formats are the known media formats in registration order. Detectors are tried in this order, so the RAW
+	// detectors of TIFF-based formats come before the TIFF matcher of filetype.
+	formats = []mediatype.Registration{
+		{Media: mediatype.JPEG{}, Dates: exifDates, Duplicates: pHash},
+		{Media: mediatype.PNG{}, Dates: exifDates, Duplicates: pHash},
+		{
+			Media:  mediatype.HEIF{},
+			Brands: []string{"heic", "heix", "heim", "heis", "hevc", "hevx", "hevm", "hevs", "avci", "avcs"},
+			Dates:  exifDates,
+		},
+		{Media: mediatype.TIFF{}, Dates: exifDates, Duplicates: pHash},
+		{Media: mediatype.QTFF{}, Brands: []string{"qt  "}, Dates: quickTimeDates, Duplicates: sha256Hash},
+		{
+			Media:      mediatype.MP4{},
+			Brands:     []string{"mp41", "mp42", "M4V ", "M4VH", "M4VP", "M4P ", "M4B ", "MSNV"},
+			Dates:      quickTimeDates,
+			Duplicates: sha256Hash,
+		},
+		{Media: mediatype.AVI{}, Dates: riffDates, Duplicates: sha256Hash},
+		{
+			Media:      mediatype.GPP{},
+			Brands:     []string{"3gp4", "3gp5", "3gp6", "3gp7", "3ge6", "3ge7", "3gg6", "3gs7"},
+			Dates:      quickTimeDates,
+			Duplicates: sha256Hash,
+		},
+		{Media: mediatype.GPP2{}, Brands: []string{"3g2a", "3g2b", "3g2c"}, Dates: quickTimeDates, Duplicates: sha256Hash},
+		// RAW sensor data cannot be decoded, so duplicates are compared by their embedded previews. CR2 is identified
+		// by the built-in matcher of filetype.
+		{Media: mediatype.DNG{}, Detect: rawDetector(rawdata.DNG), Dates: exifDates, Duplicates: previewPHash},
+		{Media: mediatype.CR2{}, Dates: exifDates, Duplicates: previewPHash},
+		{Media: mediatype.NEF{}, Detect: rawDetector(rawdata.NEF), Dates: exifDates, Duplicates: previewPHash},
+		{Media: mediatype.ARW{}, Detect: rawDetector(rawdata.ARW), Dates: exifDates, Duplicates: previewPHash},
+		{Media: mediatype.ORF{}, Detect: rawDetector(rawdata.ORF), Dates: exifDates, Duplicates: previewPHash},
+		{Media: mediatype.RW2{}, Detect: rawDetector(rawdata.RW2), Dates: exifDates, Duplicates: previewPHash},
+		{Media: mediatype.PEF{}, Detect: rawDetector(rawdata.PEF), Dates: exifDates, Duplicates: previewPHash},
+		{Media: mediatype.RAF{}, Detect: rawDetector(rawdata.RAF), Dates: exifDates, Duplicates: previewPHash},
+		{
+			Media:      mediatype.CR3{},
+			Brands:     []string{"crx "},
+			Dates:      cr3Dates(),
+			Duplicates: mediatype.Comparer{Name: "thumbnail-phash", Compare: compareUsingCR3ThumbnailPHash},
+		},
+		{Media: mediatype.WebP{}, Dates: blockDates(riffdata.GetWebPExif), Duplicates: pHash},
+		{Media: mediatype.MKV{}, Dates: matroskaDates, Duplicates: sha256Hash},
+		{Media: mediatype.WebM{}, Dates: matroskaDates, Duplicates: sha256Hash},
+		// transport streams have no magic number, instead they are identified by the sync byte starting each packet
+		{Media: mediatype.MTS{}, Detect: avchddata.IsTransportStream, Dates: avchdDates, Duplicates: sha256Hash},
+		{Media: mediatype.MPG{}, Dates: moiDates, Duplicates: sha256Hash},
+		{Media: mediatype.WAV{}, Dates: riffDates, Duplicates: sha256Hash},
+		{Media: mediatype.MP3{}, Dates: id3Dates, Duplicates: sha256Hash},
+		{Media: mediatype.M4A{}, Brands: []string{"M4A "}, Dates: quickTimeDates, Duplicates: sha256Hash},
+		// AVIF shares the HEIF container and is told apart by its ftyp brands
+		{Media: mediatype.AVIF{}, Brands: []string{"avif", "avis"}, Dates: exifDates},
+		{Media: mediatype.JXL{}, Detect: isJXL, Dates: blockDates(moovdata.GetJXLExif)},
+	}
+
+	registerOnce sync.Once
+	registerErr  error
+)
+
+// Register registers the known media formats with mediatype. It must be called before media is identified. Calling
+// it again has no effect.
+func Register() error {
+	registerOnce.Do(func() {
+		for _, r := range formats {
+			if err := mediatype.Register(r); err != nil {
+				registerErr = err
+				return
+			}
+		}
+	})
+	return registerErr
+}
+
+// rawDetector returns a detector of the RAW format with the provided extension
+func rawDetector(ext string) func([]byte) bool {
+	return func(header []byte) bool {
+		return rawdata.Format(header) == ext
+	}
+}
+
+func isJXL(buf []byte) bool {
+	return bytes.HasPrefix(buf, jxlCodestream) || bytes.HasPrefix(buf, jxlContainer)
+}
+
+// cr3Dates returns the extractor of Canon CR3 media storing its EXIF IFDs as TIFF blocks in ISOBMFF boxes
+func cr3Dates() dateresolver.Extractor {
+	getTime := func(getTime func(exifdata.TIFFBlocks) (time.Time, error)) func(string) (time.Time, error) {
+		return func(path string) (time.Time, error) {
+			md, err := moovdata.GetCR3Metadata(path)
+			if err != nil {
+				return time.Time{}, err
+			}
+			return getTime(md.TIFFBlocks)
+		}
+	}
+	return dateresolver.Extractor{
+		Dates: map[string]func(string) (time.Time, error){
+			dateresolver.ExifOriginal:  getTime(exifdata.TIFFBlocks.GetDateTimeOriginal),
+			dateresolver.ExifDigitized: getTime(exifdata.TIFFBlocks.GetDateTimeDigitized),
+			dateresolver.IFD0DateTime:  getTime(exifdata.TIFFBlocks.GetDateTime),
+			dateresolver.GPS:           getTime(exifdata.TIFFBlocks.GetGPSTime),
+		},
+		Camera: func(path string) (exifdata.Camera, error) {
+			md, err := moovdata.GetCR3Metadata(path)
+			if err != nil {
+				return exifdata.Camera{}, err
+			}
+			return md.GetCamera()
+		},
+	}
+}
+
+// blockDates returns the extractor of media embedding an EXIF block in its container, e.g. the EXIF chunk of WebP
+// files
+func blockDates(getBlock func(string) (exifdata.Block, error)) dateresolver.Extractor {
+	getTime := func(getTime func(exifdata.Block) (time.Time, error)) func(string) (time.Time, error) {
+		return func(path string) (time.Time, error) {
+			block, err := getBlock(path)
+			if err != nil {
+				return time.Time{}, err
+			}
+			return getTime(block)
+		}
+	}
+	return dateresolver.Extractor{
+		Dates: map[string]func(string) (time.Time, error){
+			dateresolver.ExifOriginal:  getTime(exifdata.Block.GetDateTimeOriginal),
+			dateresolver.ExifDigitized: getTime(exifdata.Block.GetDateTimeDigitized),
+			dateresolver.IFD0DateTime:  getTime(exifdata.Block.GetDateTime),
+			dateresolver.GPS:           getTime(exifdata.Block.GetGPSTime),
+		},
+		Camera: func(path string) (exifdata.Camera, error) {
+			block, err := getBlock(path)
+			if err != nil {
+				return exifdata.Camera{}, err
+			}
+			return block.GetCamera()
+		},
+	}
+}
@@ -0,0 +1,167 @@
+package mediaformats
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dtrejod/goexif/internal/mediatype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	if err := Register(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
+
+func TestNewFormat(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", t.Name())
+	require.NoError(t, err)
+	defer func() {
+		err := os.RemoveAll(tmpDir)
+		require.NoError(t, err)
+	}()
+
+	// Test that NewFormat() returns a Format with the correct media type
+	for _, mt := range mediatype.AllKnownMediaTypes() {
+		// Test with a file extension
+		tmpFile, err := os.CreateTemp(tmpDir, fmt.Sprintf("*.%s", mt.Ext()))
+		require.NoError(t, err)
+		defer tmpFile.Close()
+
+		f, err := mediatype.NewFormat(tmpFile.Name(), false)
+		assert.NoError(t, err)
+		assert.Equal(t, mt, f.MediaType())
+	}
+}
+
+func TestEqualFormats(t *testing.T) {
+	tmpDir := t.TempDir()
+	newFormat := func(name string) mediatype.Format {
+		path := filepath.Join(tmpDir, name)
+		require.NoError(t, os.WriteFile(path, nil, 0644))
+		f, err := mediatype.NewFormat(path, false)
+		require.NoError(t, err)
+		return f
+	}
+
+	jpeg := newFormat("a.jpg")
+	assert.True(t, mediatype.EqualFormats(jpeg, newFormat("b.JPEG")), "equal")
+	assert.False(t, mediatype.EqualFormats(jpeg, newFormat("c.png")), "different")
+
+	unknown := newFormat("d.bin")
+	assert.True(t, mediatype.EqualFormats(unknown, newFormat("e.bin")), "unknown")
+	assert.False(t, mediatype.EqualFormats(jpeg, unknown), "known and unknown")
+
+	// NewFormat returns a nil media type with its error
+	missing, err := mediatype.NewFormat(filepath.Join(tmpDir, "missing.jpg"), true)
+	require.Error(t, err)
+	assert.False(t, mediatype.EqualFormats(missing, missing), "nil")
+	assert.False(t, mediatype.EqualFormats(missing, jpeg), "nil and known")
+}
+
+func TestNewFormatRawSignature(t *testing.T) {
+	tiff := func(magic string, ifd0 ...byte) []byte {
+		return append(append([]byte(magic), 8, 0, 0, 0), ifd0...)
+	}
+	// entry returns a little-endian IFD0 with a single entry holding a value of at most 4 bytes
+	entry := func(tag uint16, typ uint16, value string) []byte {
+		ifd := []byte{1, 0, byte(tag), byte(tag >> 8), byte(typ), 0, byte(len(value)), 0, 0, 0}
+		ifd = append(ifd, value...)
+		return append(ifd, make([]byte, 4-len(value)+4)...)
+	}
+
+	for name, tc := range map[string]struct {
+		data     []byte
+		expected mediatype.MediaType
+	}{
+		"dng":            {tiff("II*\x00", entry(0xc612, 1, "\x01\x04\x00\x00")...), mediatype.DNG{}},
+		"cr2":            {tiff("II*\x00", []byte("CR\x02\x00\x00\x00\x00\x00")...), mediatype.CR2{}},
+		"orf":            {tiff("IIRO", entry(0x010f, 2, "OLY")...), mediatype.ORF{}},
+		"rw2":            {tiff("IIU\x00", entry(0x010f, 2, "PAN")...), mediatype.RW2{}},
+		"raf":            {append([]byte("FUJIFILMCCD-RAW 0201"), make([]byte, 80)...), mediatype.RAF{}},
+		"cr3":            {[]byte("\x00\x00\x00\x18ftypcrx \x00\x00\x00\x01crx isom"), mediatype.CR3{}},
+		"tiff":           {tiff("II*\x00", entry(0x010f, 2, "CAN")...), mediatype.TIFF{}},
+		"avif":           {[]byte("\x00\x00\x00\x1cftypavif\x00\x00\x00\x00avifmif1miaf"), mediatype.AVIF{}},
+		"avif mif1":      {[]byte("\x00\x00\x00\x1cftypmif1\x00\x00\x00\x00mif1avifmiaf"), mediatype.AVIF{}},
+		"heic":           {[]byte("\x00\x00\x00\x1cftypmif1\x00\x00\x00\x00mif1heicmiaf"), mediatype.HEIF{}},
+		"jxl":            {[]byte("\x00\x00\x00\x0cJXL \x0d\x0a\x87\x0a\x00\x00\x00\x14ftypjxl \x00\x00\x00\x00jxl "), mediatype.JXL{}},
+		"jxl codestream": {[]byte("\xff\x0a\xfa\x1f\x00\x00\x00\x00"), mediatype.JXL{}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "image.bin")
+			require.NoError(t, os.WriteFile(path, tc.data, 0644))
+
+			f, err := mediatype.NewFormat(path, true)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, f.MediaType())
+		})
+	}
+}
+
+func TestNewFormatISOBMFF(t *testing.T) {
+	box := func(boxType string, payload ...string) []byte {
+		var data []byte
+		for _, p := range payload {
+			data = append(data, p...)
+		}
+		return append(binary.BigEndian.AppendUint32(nil, uint32(8+len(data))), append([]byte(boxType), data...)...)
+	}
+	ftyp := func(major string, compatible ...string) []byte {
+		return box("ftyp", append([]string{major, "\x00\x00\x02\x00"}, compatible...)...)
+	}
+
+	for name, tc := range map[string]struct {
+		filename   string
+		data       []byte
+		expected   mediatype.MediaType
+		confidence mediatype.Confidence
+	}{
+		"mov":                {"video.mp4", ftyp("qt  ", "qt  "), mediatype.QTFF{}, mediatype.HighConfidence},
+		"legacy moov":        {"video.mp4", box("moov", string(box("mvhd"))), mediatype.QTFF{}, mediatype.HighConfidence},
+		"legacy wide":        {"video.mp4", append(box("wide"), box("mdat")...), mediatype.QTFF{}, mediatype.HighConfidence},
+		"mp4":                {"video.mov", ftyp("isom", "isom", "iso2", "avc1", "mp41"), mediatype.MP4{}, mediatype.HighConfidence},
+		"mp4 camera brand":   {"video.mov", ftyp("XAVC", "XAVC", "mp42", "iso2"), mediatype.MP4{}, mediatype.HighConfidence},
+		"mp4 after padding":  {"video.mov", append(box("free", "\x00\x00"), ftyp("mp42", "isom")...), mediatype.MP4{}, mediatype.HighConfidence},
+		"3gp":                {"video.mp4", ftyp("3gp4", "isom", "3gp4"), mediatype.GPP{}, mediatype.HighConfidence},
+		"3g2":                {"video.mp4", ftyp("3g2a", "3g2a"), mediatype.GPP2{}, mediatype.HighConfidence},
+		"m4a":                {"audio.mp4", ftyp("M4A ", "M4A ", "mp42", "isom"), mediatype.M4A{}, mediatype.HighConfidence},
+		"heic":               {"image.avif", ftyp("mif1", "mif1", "heic"), mediatype.HEIF{}, mediatype.HighConfidence},
+		"avif":               {"image.heic", ftyp("avif", "avif", "mif1", "miaf"), mediatype.AVIF{}, mediatype.HighConfidence},
+		"cr3":                {"image.mov", ftyp("crx ", "crx ", "isom"), mediatype.CR3{}, mediatype.HighConfidence},
+		"generic brands":     {"video.mov", ftyp("isom", "isom", "iso2", "avc1"), mediatype.QTFF{}, mediatype.LowConfidence},
+		"conflicting brands": {"image.heic", ftyp("mif1", "mif1", "heic", "avif"), mediatype.HEIF{}, mediatype.LowConfidence},
+		"unknown extension":  {"video.bin", ftyp("isom", "isom", "iso2"), mediatype.Unknown{}, mediatype.LowConfidence},
+		"not isobmff":        {"image.mov", []byte("\xff\xd8\xff\xe0\x00\x10JFIF\x00"), mediatype.JPEG{}, mediatype.HighConfidence},
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tc.filename)
+			require.NoError(t, os.WriteFile(path, tc.data, 0644))
+
+			f, err := mediatype.NewFormat(path, true)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, f.MediaType())
+			assert.Equal(t, tc.confidence, f.Confidence())
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	require.NoError(t, Register())
+	assert.Equal(t, mediatype.JPEG{}, mediatype.AllKnownMediaTypes()[0])
+
+	r, ok := mediatype.Lookup(mediatype.CR3{})
+	require.True(t, ok)
+	assert.Equal(t, []string{"exif-original", "exif-digitized", "ifd0-datetime", "gps"}, r.Dates.Sources())
+	assert.Equal(t, "thumbnail-phash", r.Duplicates.Name)
+
+	r, ok = mediatype.Lookup(mediatype.HEIF{})
+	require.True(t, ok)
+	assert.Nil(t, r.Duplicates.Compare)
+}
@@ -6,7 +6,11 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
 
+	"github.com/dtrejod/goexif/internal/clockskew"
+	"github.com/dtrejod/goexif/internal/dateresolver"
+	"github.com/dtrejod/goexif/internal/filenamedata"
 	"github.com/dtrejod/goexif/internal/ilog"
 	"github.com/dtrejod/goexif/internal/visitors"
 	"go.uber.org/zap"
@@ -49,9 +53,17 @@ type builderOptions struct {
 	overwriteExisting       bool
 	stopWalkOnError         bool
 	detectDuplicates        bool
+	useGPSTime              bool
+	pairLivePhotos          bool
+	groupCompanions         bool
 
 	allowedFileTypes []string
 	blocklist        []*regexp.Regexp
+	filenameParser   *filenamedata.Parser
+	dateSources      []string
+	dateValidator    *dateresolver.Validator
+	clockSkew        clockskew.Rules
+	companionExts    []string
 
 	sourceDirectory      *string
 	destinationDirectory *string
@@ -61,8 +73,9 @@ type builderOptions struct {
 // WithSourceDirectory Option is the only required option.
 func NewSorter(ctx context.Context, opts ...Option) (Sorter, error) {
 	cfg := builderOptions{
-		allowedFileTypes: uniqLoweredSlice(DefaultFileTypes),
+		allowedFileTypes: uniqLoweredSlice(DefaultFileTypes()),
 		blocklist:        DefaultBlocklist,
+		dateValidator:    dateresolver.NewValidator(),
 	}
 
 	for _, opt := range opts {
@@ -80,7 +93,31 @@ func NewSorter(ctx context.Context, opts ...Option) (Sorter, error) {
 		return nil, err
 	}
 
-	ilog.FromContext(ctx).Info("Sorter configuration.", zap.String("configuration", fmt.Sprintf("%+v", cfg)))
+	dateSources := cfg.dateSources
+	if len(dateSources) == 0 {
+		dateSources = dateresolver.Defaults(cfg.useGPSTime, cfg.filenameParser != nil, cfg.useLastModifiedDate)
+	}
+	resolver, err := dateresolver.NewFromNames(dateSources, cfg.filenameParser)
+	if err != nil {
+		err = fmt.Errorf("%w: %v", errInvalidConfig, err)
+		ilog.FromContext(ctx).Error("Failed to build sorter", zap.Error(err))
+		return nil, err
+	}
+	resolver.SetValidator(cfg.dateValidator)
+	resolver.SetClockSkew(cfg.clockSkew)
+
+	var livePhotos *livePhotoIndex
+	if cfg.pairLivePhotos {
+		livePhotos = newLivePhotoIndex(ctx)
+	}
+	var companions *companionIndex
+	if cfg.groupCompanions {
+		companions = newCompanionIndex(cfg.companionExts)
+	}
+
+	ilog.FromContext(ctx).Info("Sorter configuration.",
+		zap.String("configuration", fmt.Sprintf("%+v", cfg)),
+		zap.Stringer("dateSources", resolver))
 	return &traverser{
 		useInputMagicSignature: cfg.useInputMagicSignature,
 		stopWalkOnError:        cfg.stopWalkOnError,
@@ -89,6 +126,8 @@ func NewSorter(ctx context.Context, opts ...Option) (Sorter, error) {
 		sourceDirectory:        *cfg.sourceDirectory,
 
 		extVisitorFunc: visitors.NewMediaExtAliases(ctx),
+		livePhotos:     livePhotos,
+		companions:     companions,
 		progressTracker: &progressTracker{
 			currentMediaIndex: 0,
 			totalMediaFiles:   0,
@@ -100,10 +139,11 @@ func NewSorter(ctx context.Context, opts ...Option) (Sorter, error) {
 			detectDuplicates:       cfg.detectDuplicates,
 			dryRun:                 cfg.dryRun,
 			overwriteExisting:      cfg.overwriteExisting,
+			dateSources:            resolver.Names(),
 			mediaMetadataVisitorFunc: visitors.NewMediaMetadataFilename(
 				ctx,
 				cfg.destinationDirectory,
-				cfg.useLastModifiedDate,
+				resolver,
 				cfg.timestampAsFilename,
 				cfg.useOutputMagicSignature,
 			),
@@ -120,6 +160,7 @@ func WithDryRun() Option {
 }
 
 // WithTimestampAsFilename instructs the sorter to rename the source file using it's timestamp and file extension.
+// Timestamps with sub-second precision include the milliseconds so burst shots do not collide.
 // Note: This option can help eliminate duplicate images during sorting.
 func WithTimestampAsFilename() Option {
 	return builderFunc(func(b *builderOptions) error {
@@ -138,6 +179,111 @@ func WithLastModifiedFallback() Option {
 	})
 }
 
+// WithGPSTime instructs the sorter to prefer the GPS UTC time recorded in image metadata over the camera's capture
+// time. The local time is inferred from the GPS coordinates so media is sorted by the day it was taken where it was
+// taken, even when the camera clock was never adjusted. Images without GPS time use their capture time.
+func WithGPSTime() Option {
+	return builderFunc(func(b *builderOptions) error {
+		b.useGPSTime = true
+		return nil
+	})
+}
+
+// WithFilenameFallback instructs the sorter to fallback to a date found in the file's name if there is no media
+// metadata, e.g. IMG_20200626_231926.jpg or "Screenshot 2021-03-04 at 10.11.12.png". Custom patterns are regular
+// expressions with the named groups year, month and day, and optionally hour, minute, second, ms and ampm. They are
+// tried before the built-in patterns. The filename date is preferred over the last modified fallback.
+func WithFilenameFallback(patterns []string) Option {
+	return builderFunc(func(b *builderOptions) error {
+		p, err := filenamedata.NewParser(patterns)
+		if err != nil {
+			return fmt.Errorf("%w: %v", errInvalidConfig, err)
+		}
+		b.filenameParser = p
+		return nil
+	})
+}
+
+// WithDateSources is an ordered list of date sources used to date media, e.g. exif-original,xmp,filename,mtime. The
+// first source that finds a date wins. When set, then the sources enabled by WithGPSTime, WithFilenameFallback and
+// WithLastModifiedFallback are ignored; custom filename patterns still apply to the filename source.
+func WithDateSources(names []string) Option {
+	return builderFunc(func(b *builderOptions) error {
+		for _, name := range names {
+			if _, err := dateresolver.NewSource(name, nil); err != nil {
+				return fmt.Errorf("%w: %v", errInvalidConfig, err)
+			}
+		}
+		b.dateSources = names
+		return nil
+	})
+}
+
+// WithMinDate sets the earliest valid media date. Media dated before it is treated as having no date so the next date
+// source is tried. A zero time removes the lower bound. Defaults to dateresolver.DefaultMinDate.
+func WithMinDate(t time.Time) Option {
+	return builderFunc(func(b *builderOptions) error {
+		b.dateValidator.Min = t
+		return nil
+	})
+}
+
+// WithMaxDate sets the latest valid media date. Media dated after it is treated as having no date so the next date
+// source is tried. A zero time removes the upper bound. Defaults to a day from now.
+func WithMaxDate(t time.Time) Option {
+	return builderFunc(func(b *builderOptions) error {
+		b.dateValidator.Max = t
+		return nil
+	})
+}
+
+// WithSuspiciousDates replaces the dates that are treated as no date, such as the 2000-01-01 default of cameras with a
+// reset clock. An empty list accepts all dates within bounds. Defaults to dateresolver.DefaultSuspiciousDates.
+func WithSuspiciousDates(dates []time.Time) Option {
+	return builderFunc(func(b *builderOptions) error {
+		b.dateValidator.SuspiciousDates = dates
+		return nil
+	})
+}
+
+// WithClockSkewRules loads the JSON rule file of cameras whose clock was set wrong. Dates recorded by a matching
+// camera's clock are shifted before the media is sorted. See clockskew.Load.
+func WithClockSkewRules(path string) Option {
+	return builderFunc(func(b *builderOptions) error {
+		rules, err := clockskew.Load(path)
+		if err != nil {
+			return fmt.Errorf("%w: %v", errInvalidConfig, err)
+		}
+		b.clockSkew = rules
+		return nil
+	})
+}
+
+// WithLivePhotos instructs the sorter to keep the image and video of Apple Live Photos together. Pairs are found during
+// the pre-scan by the content identifier they share. The video is not dated on its own, instead it follows the image
+// to its destination directory and name.
+func WithLivePhotos() Option {
+	return builderFunc(func(b *builderOptions) error {
+		b.pairLivePhotos = true
+		return nil
+	})
+}
+
+// WithCompanions instructs the sorter to move files sharing a directory and base name as one unit, so the RAW file,
+// sidecars and thumbnails of IMG_0001.JPG follow it to its destination. Media of the group are dated from the media
+// found by the earliest configured date source. Files with the provided companion extensions, e.g. .xmp, are grouped
+// even though they are not media. Defaults to DefaultCompanionExtensions when no extensions are provided.
+func WithCompanions(extensions []string) Option {
+	return builderFunc(func(b *builderOptions) error {
+		if len(extensions) == 0 {
+			extensions = DefaultCompanionExtensions
+		}
+		b.groupCompanions = true
+		b.companionExts = uniqLoweredSlice(extensions)
+		return nil
+	})
+}
+
 // WithInputFileMagicSignature instructs the sorter to idenitify media files using the
 // file's magic signature ignoring the exisiting file extension on the media.
 // See the manual page for file(1) to understand how this works.
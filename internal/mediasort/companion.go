@@ -0,0 +1,115 @@
+package mediasort
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/dtrejod/goexif/internal/mediatype"
+)
+
+// DefaultCompanionExtensions are the extensions of non-media files that are moved together with the media sharing
+// their base name, such as editing sidecars, thumbnails and Google Takeout metadata. Camera RAW files are media, so
+// they join the group of their base name as media when their file type is sorted, and as a companion otherwise.
+var DefaultCompanionExtensions = []string{".xmp", ".aae", ".thm", ".json"}
+
+// companionIndex groups media and companion files found during the pre-scan that share a directory and base name, so
+// IMG_0001.JPG, IMG_0001.CR2 and IMG_0001.JPG.xmp are moved as one unit. Base names are matched case-insensitive.
+type companionIndex struct {
+	extensions map[string]struct{}
+
+	// media are the media files of each group in walk order
+	media map[string][]mediatype.Format
+	// mediaPaths are the paths of all media files
+	mediaPaths map[string]struct{}
+	// companions are the companion files that may belong to each group. A companion is indexed under every base name
+	// it could share, e.g. IMG_0001.JPG.xmp is indexed under IMG_0001.JPG and IMG_0001.
+	companions map[string][]string
+	// handled are the groups that were handled during the sort
+	handled map[string]struct{}
+}
+
+func newCompanionIndex(extensions []string) *companionIndex {
+	exts := make(map[string]struct{}, len(extensions))
+	for _, ext := range extensions {
+		exts["."+strings.TrimPrefix(strings.ToLower(ext), ".")] = struct{}{}
+	}
+	return &companionIndex{
+		extensions: exts,
+		media:      make(map[string][]mediatype.Format),
+		mediaPaths: make(map[string]struct{}),
+		companions: make(map[string][]string),
+		handled:    make(map[string]struct{}),
+	}
+}
+
+// isCompanion returns true if the provided path has a companion extension
+func (c *companionIndex) isCompanion(path string) bool {
+	_, ok := c.extensions[strings.ToLower(filepath.Ext(path))]
+	return ok
+}
+
+// addMedia records the provided media as a member of the group of its base name
+func (c *companionIndex) addMedia(path string, srcMedia mediatype.Format) {
+	key := groupKey(path)
+	c.media[key] = append(c.media[key], srcMedia)
+	c.mediaPaths[path] = struct{}{}
+}
+
+// addCompanion records the provided companion file under every base name it could share
+func (c *companionIndex) addCompanion(path string) {
+	for _, key := range candidateGroupKeys(path) {
+		c.companions[key] = append(c.companions[key], path)
+	}
+}
+
+// group returns the media and companion files of the group of the provided media. The second and later calls for the
+// same group return false, so each group is handled once.
+func (c *companionIndex) group(path string) ([]mediatype.Format, []string, bool) {
+	key := groupKey(path)
+	if _, ok := c.handled[key]; ok {
+		return nil, nil, false
+	}
+	c.handled[key] = struct{}{}
+
+	var companions []string
+	for _, companion := range c.companions[key] {
+		// companions that are media themselves are already members of their group
+		if _, ok := c.mediaPaths[companion]; ok {
+			continue
+		}
+		if c.ownerKey(companion) == key {
+			companions = append(companions, companion)
+		}
+	}
+	return c.media[key], companions, true
+}
+
+// ownerKey returns the longest base name of the companion that is shared by media, so IMG_0001.JPG.xmp belongs to
+// IMG_0001.JPG when found, otherwise to IMG_0001.JPG.png.
+func (c *companionIndex) ownerKey(path string) string {
+	for _, key := range candidateGroupKeys(path) {
+		if _, ok := c.media[key]; ok {
+			return key
+		}
+	}
+	return ""
+}
+
+// groupKey returns the group of a media file, which is its directory and lowered base name
+func groupKey(path string) string {
+	dir, name := filepath.Split(path)
+	return filepath.Join(dir, strings.ToLower(strings.TrimSuffix(name, filepath.Ext(name))))
+}
+
+// candidateGroupKeys returns the groups a companion file could belong to from the longest to the shortest base name
+func candidateGroupKeys(path string) []string {
+	dir, name := filepath.Split(path)
+	name = strings.ToLower(name)
+
+	var keys []string
+	for ext := filepath.Ext(name); ext != "" && ext != name; ext = filepath.Ext(name) {
+		name = strings.TrimSuffix(name, ext)
+		keys = append(keys, filepath.Join(dir, name))
+	}
+	return keys
+}
@@ -0,0 +1,132 @@
+package mediasort
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dtrejod/goexif/internal/mediaformats"
+	"github.com/dtrejod/goexif/internal/mediatype"
+	"github.com/dtrejod/goexif/internal/visitors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	if err := mediaformats.Register(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
+
+func TestFilteredMediaFollowsGroup(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	writeFiles(t, map[string]string{
+		filepath.Join(src, "IMG_0001.JPG"): "jpg",
+		filepath.Join(src, "IMG_0001.CR2"): "cr2",
+		filepath.Join(src, "IMG_0002.CR2"): "cr2",
+	})
+
+	sorter := &traverser{
+		sourceDirectory:  src,
+		allowedFileTypes: []string{mediatype.JPEG{}.String()},
+		fileHandler: &metadataFileHandler{mediaMetadataVisitorFunc: stubMetadata{
+			filepath.Join(src, "IMG_0001.JPG"): {OutPath: filepath.Join(dir, "out/1593213566.JPG")},
+		}},
+		progressTracker: &progressTracker{},
+		extVisitorFunc:  visitors.NewMediaExtAliases(ctx),
+		companions:      newCompanionIndex(DefaultCompanionExtensions),
+	}
+	require.NoError(t, sorter.Run(ctx))
+
+	// the RAW is not sorted on its own, but it follows its JPEG
+	assertFiles(t, dir, map[string]string{
+		filepath.Join(dir, "out/1593213566.JPG"): "jpg",
+		filepath.Join(dir, "out/1593213566.CR2"): "cr2",
+		filepath.Join(src, "IMG_0002.CR2"):       "cr2",
+	})
+}
+
+func TestCompanionGroup(t *testing.T) {
+	dir := filepath.Join("photos", "trip")
+	path := func(name string) string {
+		return filepath.Join(dir, name)
+	}
+
+	for name, tc := range map[string]struct {
+		media              []string
+		companions         []string
+		group              string
+		expectedMedia      []string
+		expectedCompanions []string
+	}{
+		"raw and sidecars": {
+			media:              []string{"IMG_0001.JPG", "IMG_0001.CR2", "IMG_0002.JPG"},
+			companions:         []string{"IMG_0001.JPG.xmp", "IMG_0001.aae", "IMG_0002.xmp"},
+			group:              "IMG_0001.JPG",
+			expectedMedia:      []string{"IMG_0001.JPG", "IMG_0001.CR2"},
+			expectedCompanions: []string{"IMG_0001.JPG.xmp", "IMG_0001.aae"},
+		},
+		"case-insensitive base name": {
+			media:              []string{"img_0003.jpg"},
+			companions:         []string{"IMG_0003.THM"},
+			group:              "img_0003.jpg",
+			expectedMedia:      []string{"img_0003.jpg"},
+			expectedCompanions: []string{"IMG_0003.THM"},
+		},
+		"sidecar belongs to the longest base name": {
+			media:              []string{"IMG_0004.JPG", "IMG_0004.JPG.png"},
+			companions:         []string{"IMG_0004.JPG.xmp"},
+			group:              "IMG_0004.JPG",
+			expectedMedia:      []string{"IMG_0004.JPG"},
+			expectedCompanions: nil,
+		},
+		"media without companions": {
+			media:         []string{"IMG_0005.JPG"},
+			companions:    []string{"IMG_0006.xmp"},
+			group:         "IMG_0005.JPG",
+			expectedMedia: []string{"IMG_0005.JPG"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			index := newCompanionIndex(DefaultCompanionExtensions)
+			for _, name := range tc.companions {
+				require.True(t, index.isCompanion(path(name)))
+				index.addCompanion(path(name))
+			}
+			for _, name := range tc.media {
+				require.False(t, index.isCompanion(path(name)))
+				media, err := mediatype.NewFormat(path(name), false)
+				require.NoError(t, err)
+				index.addMedia(path(name), media)
+			}
+
+			media, companions, ok := index.group(path(tc.group))
+			require.True(t, ok)
+			var mediaPaths []string
+			for _, m := range media {
+				mediaPath, err := formatPath(m)
+				require.NoError(t, err)
+				mediaPaths = append(mediaPaths, mediaPath)
+			}
+			var expectedMedia, expectedCompanions []string
+			for _, name := range tc.expectedMedia {
+				expectedMedia = append(expectedMedia, path(name))
+			}
+			for _, name := range tc.expectedCompanions {
+				expectedCompanions = append(expectedCompanions, path(name))
+			}
+			assert.Equal(t, expectedMedia, mediaPaths)
+			assert.ElementsMatch(t, expectedCompanions, companions)
+
+			// each group is handled once
+			_, _, ok = index.group(path(tc.group))
+			assert.False(t, ok)
+		})
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/dtrejod/goexif/internal/ilog"
 	"github.com/dtrejod/goexif/internal/mediatype"
@@ -18,66 +20,199 @@ type metadataFileHandler struct {
 	dryRun                 bool
 	detectDuplicates       bool
 	overwriteExisting      bool
+	// dateSources are the ordered date source names used to pick the best dated media of a group
+	dateSources []string
 
 	mediaMetadataVisitorFunc mediatype.VisitorFunc[visitors.MediaMetadata]
 }
 
-// handle takes in a source media file, and will move it a computed output file
-// based on media metadata
-func (s *metadataFileHandler) handle(ctx context.Context, srcMedia mediatype.Format) error {
+// rename is a planned move of a source file to its output file
+type rename struct {
+	srcPath string
+	outPath string
+}
+
+// handle takes in a group of source media files sharing a base name, and will move them to a computed output file
+// based on media metadata. The group is dated from the media with the best date source and the remaining media and
+// companion files, such as sidecars or the video of a Live Photo, follow it to its output directory and name. The
+// group is moved as one unit, so either all files are moved or none are. True is returned when the group was moved, or
+// would be in a dry run.
+func (s *metadataFileHandler) handle(ctx context.Context, group []mediatype.Format, companions ...string) (bool, error) {
+	srcMedia, outPath, err := s.bestMedia(ctx, group)
+	if err != nil {
+		return false, err
+	}
+
 	visitor := mediatype.FormatWithVisitor[string](srcMedia)
 	srcPath, err := visitor.Accept(ctx, visitors.NewMediaPath(ctx))
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	logger := ilog.FromContext(ctx).With(zap.String("sourcePath", srcPath))
 	logger.Debug("Processing file...")
-	outPath, err := s.getOutputFile(ctx, srcMedia)
+	followers, err := s.followers(ctx, srcMedia, group, companions)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	logger = logger.With(zap.String("outPath", outPath))
-	if srcPath == outPath {
-		logger.Debug("Source and destination file match. Nothing to do.")
-		return nil
+	if srcPath != outPath {
+		skip, err := s.shouldSkip(ctx, srcMedia, outPath)
+		if err != nil {
+			return false, err
+		}
+		if skip {
+			// the group moves as one unit, so its other members stay with the skipped media
+			logger.Debug("Skipping moving source file...")
+			for _, path := range followers {
+				logger.Info("Media was skipped, so its group member is left in place.", zap.String("memberPath", path))
+			}
+			return false, nil
+		}
 	}
 
-	skip, err := s.shouldSkip(ctx, srcMedia, outPath)
+	renames, err := s.planCompanions(srcPath, outPath, followers)
 	if err != nil {
-		return err
+		return false, err
 	}
-	if skip {
-		logger.Debug("Skipping moving source file...")
-		return nil
+	if srcPath == outPath {
+		logger.Debug("Source and destination file match. Nothing to do.")
+	} else {
+		renames = append([]rename{{srcPath: srcPath, outPath: outPath}}, renames...)
 	}
 
 	if s.dryRun {
-		logger.Debug("Dry run, moving file...")
-		return nil
+		for _, r := range renames {
+			logger.Debug("Dry run, moving file...",
+				zap.String("sourcePath", r.srcPath),
+				zap.String("outPath", r.outPath))
+		}
+		return true, nil
 	}
 
+	if len(renames) == 0 {
+		return true, nil
+	}
 	logger.Debug("Moving file...")
 	if err := os.MkdirAll(filepath.Dir(outPath), 0755); err != nil {
-		return err
+		return false, err
 	}
-
-	if err := os.Rename(srcPath, outPath); err != nil {
-		return err
+	if err := s.moveAll(ctx, renames); err != nil {
+		return false, err
 	}
 
 	logger.Debug("Successfully moved file.")
+	return true, nil
+}
+
+// bestMedia returns the media of the group dated from the earliest of the configured date sources together with its
+// output file. Media that cannot be dated is ignored unless no media of the group can be dated.
+func (s *metadataFileHandler) bestMedia(ctx context.Context, group []mediatype.Format) (mediatype.Format, string, error) {
+	var (
+		best     mediatype.Format
+		bestPath string
+		bestRank int
+		found    bool
+		firstErr error
+	)
+	for _, media := range group {
+		visitor := mediatype.FormatWithVisitor[visitors.MediaMetadata](media)
+		mediaMetadata, err := visitor.Accept(ctx, s.mediaMetadataVisitorFunc)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+
+		rank := slices.Index(s.dateSources, mediaMetadata.DateSource)
+		if rank < 0 {
+			rank = len(s.dateSources)
+		}
+		if !found || rank < bestRank {
+			best, bestPath, bestRank, found = media, mediaMetadata.OutPath, rank, true
+		}
+	}
+
+	if !found {
+		if firstErr == nil {
+			firstErr = errors.New("no media to handle")
+		}
+		return mediatype.Format{}, "", firstErr
+	}
+	return best, bestPath, nil
+}
+
+// followers returns the paths of the group media other than the provided media together with the companion files
+func (s *metadataFileHandler) followers(ctx context.Context, srcMedia mediatype.Format, group []mediatype.Format, companions []string) ([]string, error) {
+	out := make([]string, 0, len(group)+len(companions))
+	for _, media := range group {
+		if media == srcMedia {
+			continue
+		}
+		visitor := mediatype.FormatWithVisitor[string](media)
+		path, err := visitor.Accept(ctx, visitors.NewMediaPath(ctx))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, path)
+	}
+	for _, path := range companions {
+		if !slices.Contains(out, path) {
+			out = append(out, path)
+		}
+	}
+	return out, nil
+}
+
+// planCompanions returns the moves of the companion files next to the media's output file. Companions take the name
+// of the media, but keep what follows the media's base name, so IMG_1234.MOV and IMG_1234.HEIC.xmp follow IMG_1234.HEIC
+// to 2020/06/26/1593213566.MOV and 2020/06/26/1593213566.HEIC.xmp. Destination collisions are returned as errors
+// before any file is moved.
+func (s *metadataFileHandler) planCompanions(srcPath, outPath string, companions []string) ([]rename, error) {
+	srcBase := strings.TrimSuffix(filepath.Base(srcPath), filepath.Ext(srcPath))
+	outBase := strings.TrimSuffix(outPath, filepath.Ext(outPath))
+
+	renames := make([]rename, 0, len(companions))
+	for _, companionPath := range companions {
+		companionOutPath := outBase + companionSuffix(srcBase, filepath.Base(companionPath))
+		if companionPath == companionOutPath {
+			continue
+		}
+		if _, err := os.Stat(companionOutPath); err == nil && !s.overwriteExisting {
+			return nil, fmt.Errorf("desired companion output filename collision: %s", companionOutPath)
+		}
+		renames = append(renames, rename{srcPath: companionPath, outPath: companionOutPath})
+	}
+	return renames, nil
+}
+
+// moveAll moves all files in order. When a move fails, then the files that were already moved are moved back.
+func (s *metadataFileHandler) moveAll(ctx context.Context, renames []rename) error {
+	for i, r := range renames {
+		if err := os.Rename(r.srcPath, r.outPath); err != nil {
+			for j := i - 1; j >= 0; j-- {
+				if rbErr := os.Rename(renames[j].outPath, renames[j].srcPath); rbErr != nil {
+					ilog.FromContext(ctx).Error("Failed to move file back to its source.",
+						zap.String("sourcePath", renames[j].srcPath),
+						zap.String("outPath", renames[j].outPath),
+						zap.Error(rbErr))
+				}
+			}
+			return err
+		}
+	}
 	return nil
 }
 
-func (s *metadataFileHandler) getOutputFile(ctx context.Context, media mediatype.Format) (string, error) {
-	visitor := mediatype.FormatWithVisitor[visitors.MediaMetadata](media)
-	mediaMetadata, err := visitor.Accept(ctx, s.mediaMetadataVisitorFunc)
-	if err != nil {
-		return "", err
+// companionSuffix returns what follows the base name in the companion's name, or the companion's extension when the
+// names do not share the base name
+func companionSuffix(base, name string) string {
+	if len(name) > len(base) && strings.EqualFold(name[:len(base)], base) && name[len(base)] == '.' {
+		return name[len(base):]
 	}
-	return mediaMetadata.OutPath, nil
+	return filepath.Ext(name)
 }
 
 func (s *metadataFileHandler) shouldSkip(ctx context.Context, srcMedia mediatype.Format, outPath string) (bool, error) {
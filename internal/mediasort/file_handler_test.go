@@ -0,0 +1,255 @@
+package mediasort
+
+import (
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dtrejod/goexif/internal/dateresolver"
+	"github.com/dtrejod/goexif/internal/mediatype"
+	"github.com/dtrejod/goexif/internal/visitors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubMetadata is a metadata visitor returning the metadata of each media path. Media without metadata cannot be
+// dated.
+type stubMetadata map[string]visitors.MediaMetadata
+
+func (s stubMetadata) Visit(_ context.Context, _ mediatype.Registration, path string) (visitors.MediaMetadata, error) {
+	md, ok := s[path]
+	if !ok {
+		return visitors.MediaMetadata{}, errors.New("no date found")
+	}
+	return md, nil
+}
+
+// formatPath returns the path of the media
+func formatPath(media mediatype.Format) (string, error) {
+	ctx := context.Background()
+	visitor := mediatype.FormatWithVisitor[string](media)
+	return visitor.Accept(ctx, visitors.NewMediaPath(ctx))
+}
+
+// newGroup returns the media of the provided paths
+func newGroup(t *testing.T, paths ...string) []mediatype.Format {
+	group := make([]mediatype.Format, 0, len(paths))
+	for _, path := range paths {
+		media, err := mediatype.NewFormat(path, false)
+		require.NoError(t, err)
+		group = append(group, media)
+	}
+	return group
+}
+
+// writeFiles creates files with the provided contents
+func writeFiles(t *testing.T, files map[string]string) {
+	for path, data := range files {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(data), 0644))
+	}
+}
+
+func TestBestMedia(t *testing.T) {
+	ctx := context.Background()
+	sources := []string{dateresolver.ExifOriginal, dateresolver.QuickTime, dateresolver.ModTime}
+
+	for name, tc := range map[string]struct {
+		metadata stubMetadata
+		expected string
+		err      bool
+	}{
+		"earliest date source wins": {
+			metadata: stubMetadata{
+				"IMG_0001.JPG": {OutPath: "jpg", DateSource: dateresolver.ModTime},
+				"IMG_0001.MOV": {OutPath: "mov", DateSource: dateresolver.QuickTime},
+				"IMG_0001.CR2": {OutPath: "cr2", DateSource: dateresolver.ExifOriginal},
+			},
+			expected: "cr2",
+		},
+		"first media wins a tie": {
+			metadata: stubMetadata{
+				"IMG_0001.JPG": {OutPath: "jpg", DateSource: dateresolver.ExifOriginal},
+				"IMG_0001.CR2": {OutPath: "cr2", DateSource: dateresolver.ExifOriginal},
+			},
+			expected: "jpg",
+		},
+		"unknown date source ranks last": {
+			metadata: stubMetadata{
+				"IMG_0001.JPG": {OutPath: "jpg", DateSource: dateresolver.Filename},
+				"IMG_0001.MOV": {OutPath: "mov", DateSource: dateresolver.ModTime},
+			},
+			expected: "mov",
+		},
+		"undated media is ignored": {
+			metadata: stubMetadata{
+				"IMG_0001.MOV": {OutPath: "mov", DateSource: dateresolver.ModTime},
+			},
+			expected: "mov",
+		},
+		"no media dated": {
+			metadata: stubMetadata{},
+			err:      true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			handler := &metadataFileHandler{dateSources: sources, mediaMetadataVisitorFunc: tc.metadata}
+
+			_, outPath, err := handler.bestMedia(ctx, newGroup(t, "IMG_0001.JPG", "IMG_0001.CR2", "IMG_0001.MOV"))
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, outPath)
+		})
+	}
+}
+
+func TestHandle(t *testing.T) {
+	ctx := context.Background()
+
+	for name, tc := range map[string]struct {
+		// existing are the files in the output directory before the group is handled
+		existing map[string]string
+		moved    bool
+		err      bool
+		// expected are the files found after the group is handled
+		expected map[string]string
+	}{
+		"group is moved": {
+			moved: true,
+			expected: map[string]string{
+				"out/1593213566.JPG":     "jpg",
+				"out/1593213566.CR2":     "cr2",
+				"out/1593213566.JPG.xmp": "xmp",
+			},
+		},
+		"companion collision aborts the group": {
+			existing: map[string]string{"out/1593213566.JPG.xmp": "other"},
+			err:      true,
+			expected: map[string]string{
+				"src/IMG_0001.JPG":       "jpg",
+				"src/IMG_0001.CR2":       "cr2",
+				"src/IMG_0001.JPG.xmp":   "xmp",
+				"out/1593213566.JPG.xmp": "other",
+			},
+		},
+		"media collision aborts the group": {
+			existing: map[string]string{"out/1593213566.JPG": "other"},
+			err:      true,
+			expected: map[string]string{
+				"src/IMG_0001.JPG":     "jpg",
+				"src/IMG_0001.CR2":     "cr2",
+				"src/IMG_0001.JPG.xmp": "xmp",
+				"out/1593213566.JPG":   "other",
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			abs := func(files map[string]string) map[string]string {
+				out := make(map[string]string, len(files))
+				for path, data := range files {
+					out[filepath.Join(dir, path)] = data
+				}
+				return out
+			}
+			writeFiles(t, abs(map[string]string{
+				"src/IMG_0001.JPG":     "jpg",
+				"src/IMG_0001.CR2":     "cr2",
+				"src/IMG_0001.JPG.xmp": "xmp",
+			}))
+			writeFiles(t, abs(tc.existing))
+
+			handler := &metadataFileHandler{mediaMetadataVisitorFunc: stubMetadata{
+				filepath.Join(dir, "src/IMG_0001.JPG"): {OutPath: filepath.Join(dir, "out/1593213566.JPG")},
+			}}
+			group := newGroup(t, filepath.Join(dir, "src/IMG_0001.JPG"), filepath.Join(dir, "src/IMG_0001.CR2"))
+			moved, err := handler.handle(ctx, group, filepath.Join(dir, "src/IMG_0001.JPG.xmp"))
+			if tc.err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.moved, moved)
+			assertFiles(t, dir, abs(tc.expected))
+		})
+	}
+}
+
+func TestHandleDuplicateKeepsGroup(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	img := image.NewGray(image.Rect(0, 0, 8, 8))
+	for _, path := range []string{"src/IMG_0001.png", "out/1593213566.png"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(path)), 0755))
+		f, err := os.Create(filepath.Join(dir, path))
+		require.NoError(t, err)
+		require.NoError(t, png.Encode(f, img))
+		require.NoError(t, f.Close())
+	}
+	// the duplicate was sorted before together with its sidecar
+	writeFiles(t, map[string]string{
+		filepath.Join(dir, "src/IMG_0001.png.xmp"):   "xmp",
+		filepath.Join(dir, "out/1593213566.png.xmp"): "xmp",
+	})
+
+	handler := &metadataFileHandler{
+		detectDuplicates: true,
+		mediaMetadataVisitorFunc: stubMetadata{
+			filepath.Join(dir, "src/IMG_0001.png"): {OutPath: filepath.Join(dir, "out/1593213566.png")},
+		},
+	}
+	group := newGroup(t, filepath.Join(dir, "src/IMG_0001.png"))
+	moved, err := handler.handle(ctx, group, filepath.Join(dir, "src/IMG_0001.png.xmp"))
+	require.NoError(t, err)
+	assert.False(t, moved)
+
+	// the sidecar stays with its skipped duplicate
+	assert.FileExists(t, filepath.Join(dir, "src/IMG_0001.png"))
+	assert.FileExists(t, filepath.Join(dir, "src/IMG_0001.png.xmp"))
+}
+
+func TestMoveAllRollback(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	writeFiles(t, map[string]string{
+		filepath.Join(dir, "src/a.jpg"): "a",
+		filepath.Join(dir, "src/b.xmp"): "b",
+	})
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "out"), 0755))
+
+	handler := &metadataFileHandler{}
+	err := handler.moveAll(ctx, []rename{
+		{srcPath: filepath.Join(dir, "src/a.jpg"), outPath: filepath.Join(dir, "out/a.jpg")},
+		{srcPath: filepath.Join(dir, "src/b.xmp"), outPath: filepath.Join(dir, "out/b.xmp")},
+		// the source is missing, so the rename fails
+		{srcPath: filepath.Join(dir, "src/c.aae"), outPath: filepath.Join(dir, "out/c.aae")},
+	})
+	require.Error(t, err)
+
+	assertFiles(t, dir, map[string]string{
+		filepath.Join(dir, "src/a.jpg"): "a",
+		filepath.Join(dir, "src/b.xmp"): "b",
+	})
+}
+
+// assertFiles asserts that the files under dir are exactly the expected files
+func assertFiles(t *testing.T, dir string, expected map[string]string) {
+	actual := make(map[string]string)
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		actual[path] = string(data)
+		return err
+	})
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
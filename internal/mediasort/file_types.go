@@ -0,0 +1,13 @@
+package mediasort
+
+import "github.com/dtrejod/goexif/internal/mediatype"
+
+// DefaultFileTypes returns the default media types handled by the sorter if none are specified. These are all
+// registered media types, so the media formats must be registered first.
+func DefaultFileTypes() []string {
+	types := make([]string, 0, len(mediatype.AllKnownMediaTypes()))
+	for _, media := range mediatype.AllKnownMediaTypes() {
+		types = append(types, media.String())
+	}
+	return types
+}
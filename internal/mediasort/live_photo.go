@@ -0,0 +1,105 @@
+package mediasort
+
+import (
+	"context"
+
+	"github.com/dtrejod/goexif/internal/ilog"
+	"github.com/dtrejod/goexif/internal/mediatype"
+	"github.com/dtrejod/goexif/internal/visitors"
+	"go.uber.org/zap"
+)
+
+// livePhotoIndex pairs the image and video of Apple Live Photos found during the pre-scan by their shared content
+// identifier
+type livePhotoIndex struct {
+	visitorFunc mediatype.VisitorFunc[visitors.LivePhoto]
+
+	images map[string]string
+	videos map[string]string
+
+	// videoOf maps the path of a Live Photo image to the path of its video
+	videoOf map[string]string
+	// imageOf maps the path of a Live Photo video to the path of its image
+	imageOf map[string]string
+	// moved records whether each handled image was moved together with its video
+	moved map[string]bool
+	// waiting are the paths of videos that were reached before their image was handled
+	waiting map[string]struct{}
+}
+
+func newLivePhotoIndex(ctx context.Context) *livePhotoIndex {
+	return &livePhotoIndex{
+		visitorFunc: visitors.NewLivePhoto(ctx),
+		images:      make(map[string]string),
+		videos:      make(map[string]string),
+		videoOf:     make(map[string]string),
+		imageOf:     make(map[string]string),
+		moved:       make(map[string]bool),
+		waiting:     make(map[string]struct{}),
+	}
+}
+
+// add records the content identifier of the provided media, if any, and pairs it with previously added media
+func (l *livePhotoIndex) add(ctx context.Context, path string, srcMedia mediatype.Format) {
+	visitor := mediatype.FormatWithVisitor[visitors.LivePhoto](srcMedia)
+	lp, err := visitor.Accept(ctx, l.visitorFunc)
+	if err != nil {
+		return
+	}
+
+	if lp.IsVideo {
+		l.videos[lp.ContentIdentifier] = path
+	} else {
+		l.images[lp.ContentIdentifier] = path
+	}
+
+	image, okImage := l.images[lp.ContentIdentifier]
+	video, okVideo := l.videos[lp.ContentIdentifier]
+	if !okImage || !okVideo {
+		return
+	}
+	ilog.FromContext(ctx).Debug("Found Live Photo.",
+		zap.String("image", image),
+		zap.String("video", video),
+		zap.String("contentIdentifier", lp.ContentIdentifier))
+	l.videoOf[image] = video
+	l.imageOf[video] = image
+}
+
+// companions returns the videos that follow the provided image
+func (l *livePhotoIndex) companions(path string) []string {
+	if video, ok := l.videoOf[path]; ok {
+		return []string{video}
+	}
+	return nil
+}
+
+// skipVideo returns true if the provided path is a video that follows its image. A video reached before its image was
+// handled waits for it. A video whose image was not moved is sorted on its own.
+func (l *livePhotoIndex) skipVideo(path string) bool {
+	image, ok := l.imageOf[path]
+	if !ok {
+		return false
+	}
+	moved, handled := l.moved[image]
+	if !handled {
+		l.waiting[path] = struct{}{}
+		return true
+	}
+	return moved
+}
+
+// imageHandled records whether the provided image was moved together with its video. The video is returned when it
+// waits for the image, but was not moved with it, so it must be sorted on its own.
+func (l *livePhotoIndex) imageHandled(path string, moved bool) (string, bool) {
+	video, ok := l.videoOf[path]
+	if !ok {
+		return "", false
+	}
+	l.moved[path] = moved
+	if _, waiting := l.waiting[video]; !waiting || moved {
+		return "", false
+	}
+	delete(l.waiting, video)
+	return video, true
+}
@@ -0,0 +1,94 @@
+package mediasort
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/dtrejod/goexif/internal/mediatype"
+	"github.com/dtrejod/goexif/internal/visitors"
+	"github.com/stretchr/testify/require"
+)
+
+// stubLivePhoto is a Live Photo visitor returning the content identifier of each media path
+type stubLivePhoto map[string]visitors.LivePhoto
+
+func (s stubLivePhoto) Visit(_ context.Context, _ mediatype.Registration, path string) (visitors.LivePhoto, error) {
+	lp, ok := s[filepath.Base(path)]
+	if !ok {
+		return visitors.LivePhoto{}, visitors.ErrNotLivePhoto
+	}
+	return lp, nil
+}
+
+func TestLivePhotos(t *testing.T) {
+	ctx := context.Background()
+
+	for name, tc := range map[string]struct {
+		image, video string
+		// imageDated is false when the image has no date and cannot be moved
+		imageDated bool
+		expected   map[string]string
+	}{
+		"video follows its image": {
+			image:      "IMG_0001.HEIC",
+			video:      "IMG_0001.MOV",
+			imageDated: true,
+			expected:   map[string]string{"out/1593213566.HEIC": "image", "out/1593213566.MOV": "video"},
+		},
+		"video found before its image": {
+			image:      "IMG_0002.HEIC",
+			video:      "IMG_0001.MOV",
+			imageDated: true,
+			expected:   map[string]string{"out/1593213566.HEIC": "image", "out/1593213566.MOV": "video"},
+		},
+		"video of undated image": {
+			image:    "IMG_0001.HEIC",
+			video:    "IMG_0001.MOV",
+			expected: map[string]string{"src/IMG_0001.HEIC": "image", "out/1593213567.MOV": "video"},
+		},
+		"video found before undated image": {
+			image:    "IMG_0002.HEIC",
+			video:    "IMG_0001.MOV",
+			expected: map[string]string{"src/IMG_0002.HEIC": "image", "out/1593213567.MOV": "video"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			src := filepath.Join(dir, "src")
+			writeFiles(t, map[string]string{
+				filepath.Join(src, tc.image): "image",
+				filepath.Join(src, tc.video): "video",
+			})
+
+			metadata := stubMetadata{
+				filepath.Join(src, tc.video): {OutPath: filepath.Join(dir, "out/1593213567.MOV")},
+			}
+			if tc.imageDated {
+				metadata[filepath.Join(src, tc.image)] = visitors.MediaMetadata{
+					OutPath: filepath.Join(dir, "out/1593213566.HEIC"),
+				}
+			}
+			livePhotos := newLivePhotoIndex(ctx)
+			livePhotos.visitorFunc = stubLivePhoto{
+				tc.image: {ContentIdentifier: "8E0C8AA8"},
+				tc.video: {ContentIdentifier: "8E0C8AA8", IsVideo: true},
+			}
+			sorter := &traverser{
+				sourceDirectory:  src,
+				allowedFileTypes: DefaultFileTypes(),
+				fileHandler:      &metadataFileHandler{mediaMetadataVisitorFunc: metadata},
+				progressTracker:  &progressTracker{},
+				extVisitorFunc:   visitors.NewMediaExtAliases(ctx),
+				livePhotos:       livePhotos,
+			}
+			require.NoError(t, sorter.Run(ctx))
+
+			expected := make(map[string]string, len(tc.expected))
+			for path, data := range tc.expected {
+				expected[filepath.Join(dir, path)] = data
+			}
+			assertFiles(t, dir, expected)
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/dtrejod/goexif/internal/ilog"
 	"github.com/dtrejod/goexif/internal/mediatype"
+	"github.com/dtrejod/goexif/internal/moidata"
 	"go.uber.org/zap"
 )
 
@@ -23,6 +24,10 @@ type traverser struct {
 	fileHandler     *metadataFileHandler
 	progressTracker *progressTracker
 	extVisitorFunc  mediatype.VisitorFunc[map[string]struct{}]
+	// livePhotos pairs Live Photo images and videos when configured
+	livePhotos *livePhotoIndex
+	// companions groups media and companion files sharing a base name when configured
+	companions *companionIndex
 }
 
 // Run implements Sorter
@@ -61,11 +66,19 @@ func (t *traverser) traverseFunc(ctx context.Context, isPreRun bool) fs.WalkDirF
 			return fs.SkipDir
 		}
 
+		if isPreRun && t.companions != nil && t.companions.isCompanion(path) {
+			t.companions.addCompanion(path)
+		}
+
 		srcMedia, err := mediatype.NewFormat(path, t.useInputMagicSignature)
 		if err != nil {
 			logger.Debug("Could not identify file as media file.", zap.Error(err))
 			return nil
 		}
+		if t.useInputMagicSignature && srcMedia.Confidence() == mediatype.LowConfidence {
+			logger.Warn("Could not classify file by its magic signature, so identifying it by its file extension.",
+				zap.Stringer("mediaType", srcMedia.MediaType()))
+		}
 
 		visistor := mediatype.FormatWithVisitor[map[string]struct{}](srcMedia)
 		aliases, err := visistor.Accept(ctx, t.extVisitorFunc)
@@ -76,25 +89,85 @@ func (t *traverser) traverseFunc(ctx context.Context, isPreRun bool) fs.WalkDirF
 
 		logger.Debug("Checking file.")
 		if t.skipFile(aliases) {
+			// media of a filtered out type still follows the media sharing its base name, e.g. the RAW of a JPEG
+			if isPreRun && t.companions != nil {
+				t.companions.addCompanion(path)
+			}
 			logger.Debug("File did not matchfile types allowlist, so skipping...")
 			return nil
 		}
 
 		if isPreRun {
+			if t.livePhotos != nil {
+				t.livePhotos.add(ctx, path, srcMedia)
+			}
+			if t.companions != nil {
+				t.companions.addMedia(path, srcMedia)
+			}
 			t.progressTracker.handle(ctx, true)
 			return nil
 		}
 
 		t.progressTracker.handle(ctx, false)
-		if err := t.fileHandler.handle(ctx, srcMedia); err != nil {
-			logger.Warn("Failed to handle file.", zap.Error(err))
-			if t.stopWalkOnError {
-				return err
-			}
+		if t.livePhotos != nil && t.livePhotos.skipVideo(path) {
+			logger.Debug("Live Photo video follows its image, so skipping...")
+			return nil
 		}
+		return t.sort(ctx, path, srcMedia, aliases)
+	}
+}
+
+// sort moves the provided media together with its group and companions. The video of a Live Photo image that was not
+// moved is sorted on its own when it was reached before the image.
+func (t *traverser) sort(ctx context.Context, path string, srcMedia mediatype.Format, aliases map[string]struct{}) error {
+	logger := ilog.FromContext(ctx).With(zap.String("path", path))
 
+	group := []mediatype.Format{srcMedia}
+	var companions []string
+	if t.livePhotos != nil {
+		companions = t.livePhotos.companions(path)
+	}
+	if t.companions != nil {
+		media, groupCompanions, ok := t.companions.group(path)
+		if !ok {
+			logger.Debug("File was moved with its companion group, so skipping...")
+			return nil
+		}
+		group = media
+		companions = append(companions, groupCompanions...)
+	}
+	// MOI sidecars hold the date of their MPEG-PS video, so they always follow it
+	if _, ok := aliases[mediatype.MPG{}.String()]; ok {
+		if sidecar, ok := moidata.SidecarPath(path); ok {
+			companions = append(companions, sidecar)
+		}
+	}
+	moved, err := t.fileHandler.handle(ctx, group, companions...)
+	if err != nil {
+		logger.Warn("Failed to handle file.", zap.Error(err))
+		if t.stopWalkOnError {
+			return err
+		}
+	}
+
+	if t.livePhotos == nil {
+		return nil
+	}
+	video, ok := t.livePhotos.imageHandled(path, moved)
+	if !ok {
+		return nil
+	}
+	logger.Info("Live Photo image was not moved, so sorting its video on its own.", zap.String("video", video))
+	videoMedia, err := mediatype.NewFormat(video, t.useInputMagicSignature)
+	if err != nil {
+		return nil
+	}
+	visitor := mediatype.FormatWithVisitor[map[string]struct{}](videoMedia)
+	videoAliases, err := visitor.Accept(ctx, t.extVisitorFunc)
+	if err != nil {
 		return nil
 	}
+	return t.sort(ctx, video, videoMedia, videoAliases)
 }
 
 func (t *traverser) skipDir(path string) bool {
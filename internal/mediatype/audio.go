@@ -0,0 +1,65 @@
+package mediatype
+
+// WAV identifies WAV audio, including Broadcast Wave recordings
+// REF: https://en.wikipedia.org/wiki/WAV
+type WAV struct{}
+
+// String implements Stringer interface
+func (t WAV) String() string {
+	return "wav"
+}
+
+// Ext returns the file extension
+func (t WAV) Ext() string {
+	return "." + t.String()
+}
+
+// Aliases returns known file type aliases for this media type
+func (t WAV) Aliases() map[string]struct{} {
+	return map[string]struct{}{
+		t.String(): {},
+		"bwf":      {},
+	}
+}
+
+// MP3 identifies MP3 audio
+// REF: https://en.wikipedia.org/wiki/MP3
+type MP3 struct{}
+
+// String implements Stringer interface
+func (t MP3) String() string {
+	return "mp3"
+}
+
+// Ext returns the file extension
+func (t MP3) Ext() string {
+	return "." + t.String()
+}
+
+// Aliases returns known file type aliases for this media type
+func (t MP3) Aliases() map[string]struct{} {
+	return map[string]struct{}{
+		t.String(): {},
+	}
+}
+
+// M4A identifies MPEG-4 audio, e.g. voice memos
+// REF: https://en.wikipedia.org/wiki/MP4_file_format
+type M4A struct{}
+
+// String implements Stringer interface
+func (t M4A) String() string {
+	return "m4a"
+}
+
+// Ext returns the file extension
+func (t M4A) Ext() string {
+	return "." + t.String()
+}
+
+// Aliases returns known file type aliases for this media type
+func (t M4A) Aliases() map[string]struct{} {
+	return map[string]struct{}{
+		t.String(): {},
+	}
+}
@@ -2,9 +2,7 @@ package mediatype
 
 // AVI indetifies AVI media
 // REF: https://en.wikipedia.org/wiki/Audio_Video_Interleave
-type AVI struct {
-	Path string
-}
+type AVI struct{}
 
 // String implements Stringer interface
 func (t AVI) String() string {
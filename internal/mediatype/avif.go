@@ -0,0 +1,44 @@
+package mediatype
+
+// AVIF identifies AV1 Image File Format media
+// REF: https://en.wikipedia.org/wiki/AVIF
+type AVIF struct{}
+
+// String implements Stringer interface
+func (t AVIF) String() string {
+	return "avif"
+}
+
+// Ext returns the file extension
+func (t AVIF) Ext() string {
+	return "." + t.String()
+}
+
+// Aliases returns known file type aliases for this media type
+func (t AVIF) Aliases() map[string]struct{} {
+	return map[string]struct{}{
+		t.String(): {},
+		"avifs":    {},
+	}
+}
+
+// JXL identifies JPEG XL media
+// REF: https://en.wikipedia.org/wiki/JPEG_XL
+type JXL struct{}
+
+// String implements Stringer interface
+func (t JXL) String() string {
+	return "jxl"
+}
+
+// Ext returns the file extension
+func (t JXL) Ext() string {
+	return "." + t.String()
+}
+
+// Aliases returns known file type aliases for this media type
+func (t JXL) Aliases() map[string]struct{} {
+	return map[string]struct{}{
+		t.String(): {},
+	}
+}
@@ -2,7 +2,10 @@ package mediatype
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
 	"path/filepath"
 	"strings"
 
@@ -19,58 +22,113 @@ type MediaType interface {
 	Aliases() map[string]struct{}
 }
 
+const (
+	// signatureHeaderSize is the size of the file header read to match the magic signature
+	signatureHeaderSize = 8192
+)
+
+// Confidence is how certain the identification of a media file is
+type Confidence int
+
+const (
+	// LowConfidence is media identified by its file extension only, e.g. because its magic signature could not be
+	// classified
+	LowConfidence Confidence = iota
+	// HighConfidence is media identified by its magic signature
+	HighConfidence
+)
+
+// String implements Stringer interface
+func (c Confidence) String() string {
+	if c == HighConfidence {
+		return "high"
+	}
+	return "low"
+}
+
 // Format is a container for any known media type
 type Format struct {
-	media MediaType
+	media      MediaType
+	path       string
+	confidence Confidence
 }
 
-// AllKnownMediaTypes is a list of all known media types
-var AllKnownMediaTypes = []MediaType{
-	JPEG{},
-	PNG{},
-	HEIF{},
-	TIFF{},
-	QTFF{},
-	MP4{},
-	AVI{},
-	GPP{},
-	GPP2{},
+// MediaType returns the media type of the media
+func (f Format) MediaType() MediaType {
+	return f.media
 }
 
-// NewFormat returns a new Format instance based on the file extension. If useSignature is true, then the existing file
-// extension is ignored and we use the file magic signature instead
+// Confidence returns how certain the identification of the media is
+func (f Format) Confidence() Confidence {
+	return f.confidence
+}
+
+// AllKnownMediaTypes returns the media types of all registered formats
+func AllKnownMediaTypes() []MediaType {
+	var media []MediaType
+	for _, r := range registrations {
+		media = append(media, r.Media)
+	}
+	return media
+}
+
+// NewFormat returns a new Format instance of the registered formats based on the file extension. If useSignature is
+// true, then the existing file extension is ignored and we use the file magic signature instead. ISOBMFF and QuickTime
+// files are identified by their ftyp brands; when the brands cannot be classified, e.g. files with only generic brands
+// such as isom, the file extension is used with LowConfidence rather than guessing the format.
 // REF: https://en.wikipedia.org/wiki/File_format#Magic_number
 func NewFormat(path string, useSignature bool) (Format, error) {
-	ext := strings.TrimPrefix(filepath.Ext(strings.ToLower(path)), ".")
-	if useSignature {
-		t, err := filetype.MatchFile(path)
-		if err != nil {
-			return Format{}, err
+	if !useSignature {
+		return newFormatFromExt(path), nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return Format{}, err
+	}
+	defer f.Close()
+
+	header := make([]byte, signatureHeaderSize)
+	n, err := io.ReadFull(f, header)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return Format{}, err
+	}
+
+	header = header[:n]
+
+	if media, ok := detectISOBMFF(header); ok {
+		if media == nil {
+			return newFormatFromExt(path), nil
 		}
-		ext = t.Extension
+		return Format{media: media, path: path, confidence: HighConfidence}, nil
+	}
+
+	if r, ok := detect(header); ok {
+		return Format{media: r.Media, path: path, confidence: HighConfidence}, nil
 	}
-	switch {
-	case contains(JPEG{}.Aliases(), ext):
-		return Format{media: JPEG{Path: path}}, nil
-	case contains(PNG{}.Aliases(), ext):
-		return Format{media: PNG{Path: path}}, nil
-	case contains(HEIF{}.Aliases(), ext):
-		return Format{media: HEIF{Path: path}}, nil
-	case contains(TIFF{}.Aliases(), ext):
-		return Format{media: TIFF{Path: path}}, nil
-	case contains(QTFF{}.Aliases(), ext):
-		return Format{media: QTFF{Path: path}}, nil
-	case contains(MP4{}.Aliases(), ext):
-		return Format{media: MP4{Path: path}}, nil
-	case contains(AVI{}.Aliases(), ext):
-		return Format{media: AVI{Path: path}}, nil
-	case contains(GPP{}.Aliases(), ext):
-		return Format{media: GPP{Path: path}}, nil
-	case contains(GPP2{}.Aliases(), ext):
-		return Format{media: GPP2{Path: path}}, nil
-	default:
-		return Format{media: Unknown{}}, nil
+
+	t, err := filetype.Match(header)
+	if errors.Is(err, filetype.ErrEmptyBuffer) {
+		return Format{media: Unknown{}, path: path}, nil
 	}
+	if err != nil {
+		return Format{}, err
+	}
+	r, ok := lookupAlias(t.Extension)
+	if !ok {
+		return Format{media: Unknown{}, path: path}, nil
+	}
+	return Format{media: r.Media, path: path, confidence: HighConfidence}, nil
+}
+
+// newFormatFromExt returns the Format of the file extension
+func newFormatFromExt(path string) Format {
+	ext := strings.TrimPrefix(filepath.Ext(strings.ToLower(path)), ".")
+	r, ok := lookupAlias(ext)
+	if !ok {
+		return Format{media: Unknown{}, path: path}
+	}
+	return Format{media: r.Media, path: path}
 }
 
 func contains(toMatch map[string]struct{}, s string) bool {
@@ -81,81 +139,21 @@ func contains(toMatch map[string]struct{}, s string) bool {
 // FormatWithVisitor is a generic Format union type visitor
 type FormatWithVisitor[T any] Format
 
-// VisitorFunc implements a Visitor type that handles all known Format types
+// VisitorFunc visits media by the registration of its format
 type VisitorFunc[T any] interface {
-	VisitJPEG(context.Context, JPEG) (T, error)
-	VisitPNG(context.Context, PNG) (T, error)
-	VisitHEIF(context.Context, HEIF) (T, error)
-	VisitTIFF(context.Context, TIFF) (T, error)
-	VisitQTFF(context.Context, QTFF) (T, error)
-	VisitMP4(context.Context, MP4) (T, error)
-	VisitAVI(context.Context, AVI) (T, error)
-	Visit3PG(context.Context, GPP) (T, error)
-	Visit3G2(context.Context, GPP2) (T, error)
+	Visit(ctx context.Context, format Registration, path string) (T, error)
 }
 
 // Accept visits the current media type using the visitor pattern
 func (f *FormatWithVisitor[T]) Accept(ctx context.Context, v VisitorFunc[T]) (T, error) {
-	switch f.media.(type) {
-	case JPEG:
-		return v.VisitJPEG(ctx, f.media.(JPEG))
-	case PNG:
-		return v.VisitPNG(ctx, f.media.(PNG))
-	case HEIF:
-		return v.VisitHEIF(ctx, f.media.(HEIF))
-	case TIFF:
-		return v.VisitTIFF(ctx, f.media.(TIFF))
-	case QTFF:
-		return v.VisitQTFF(ctx, f.media.(QTFF))
-	case MP4:
-		return v.VisitMP4(ctx, f.media.(MP4))
-	case AVI:
-		return v.VisitAVI(ctx, f.media.(AVI))
-	case GPP:
-		return v.Visit3PG(ctx, f.media.(GPP))
-	case GPP2:
-		return v.Visit3G2(ctx, f.media.(GPP2))
-	case Unknown:
-	default:
+	r, ok := Lookup(f.media)
+	if !ok {
+		return *new(T), fmt.Errorf("unknown media type")
 	}
-	return *new(T), fmt.Errorf("unknown media type")
+	return v.Visit(ctx, r, f.path)
 }
 
 // EqualFormats returns true if two Formats are of the same media type
 func EqualFormats(a, b Format) bool {
-	// Use type assertions to compare the types of media
-	switch a.media.(type) {
-	case JPEG:
-		_, ok := b.media.(JPEG)
-		return ok
-	case PNG:
-		_, ok := b.media.(PNG)
-		return ok
-	case HEIF:
-		_, ok := b.media.(HEIF)
-		return ok
-	case TIFF:
-		_, ok := b.media.(TIFF)
-		return ok
-	case QTFF:
-		_, ok := b.media.(QTFF)
-		return ok
-	case MP4:
-		_, ok := b.media.(MP4)
-		return ok
-	case AVI:
-		_, ok := b.media.(AVI)
-		return ok
-	case GPP:
-		_, ok := b.media.(GPP)
-		return ok
-	case GPP2:
-		_, ok := b.media.(GPP2)
-		return ok
-	case Unknown:
-		_, ok := b.media.(Unknown)
-		return ok
-	default:
-		return false
-	}
+	return a.media != nil && a.media == b.media
 }
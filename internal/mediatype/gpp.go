@@ -2,9 +2,7 @@ package mediatype
 
 // GPP indetifies 3GP media
 // REF: https://en.wikipedia.org/wiki/3GP_and_3G2
-type GPP struct {
-	Path string
-}
+type GPP struct{}
 
 // String implements Stringer interface
 func (t GPP) String() string {
@@ -26,9 +24,7 @@ func (t GPP) Aliases() map[string]struct{} {
 
 // GPP2 indetifies 3G2 media
 // REF: https://en.wikipedia.org/wiki/3GP_and_3G2
-type GPP2 struct {
-	Path string
-}
+type GPP2 struct{}
 
 // String implements Stringer interface
 func (t GPP2) String() string {
@@ -2,9 +2,7 @@ package mediatype
 
 // HEIF identifies HEIF media
 // Ref: https://en.wikipedia.org/wiki/High_Efficiency_Image_File_Format
-type HEIF struct {
-	Path string
-}
+type HEIF struct{}
 
 // String implements Stringer interface
 func (t HEIF) String() string {
@@ -25,7 +23,6 @@ func (t HEIF) Aliases() map[string]struct{} {
 		"heics":    {},
 		"avci":     {},
 		"avcs":     {},
-		"avif":     {},
 		"hif":      {},
 	}
 }
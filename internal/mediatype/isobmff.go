@@ -0,0 +1,111 @@
+package mediatype
+
+import (
+	"encoding/binary"
+	"slices"
+)
+
+var (
+	// legacyQuickTimeAtoms start QuickTime movies written before the ftyp atom was introduced. They map to the valid
+	// atom sizes: moov holds at least its mvhd atom, wide and pnot have a fixed size, and only mdat may extend to the
+	// end of the file.
+	// Ref: https://developer.apple.com/documentation/quicktime-file-format/movie_atoms
+	legacyQuickTimeAtoms = map[string]atomSize{
+		"moov": {min: 16},
+		"mdat": {min: 8, toEOF: true},
+		"wide": {min: 8, max: 8},
+		"pnot": {min: 20, max: 20},
+	}
+	// paddingBoxes may precede the ftyp box
+	paddingBoxes = []string{"free", "skip"}
+)
+
+// atomSize is the range of valid sizes of an atom. A max of 0 is unbounded, and toEOF allows a size of 0.
+type atomSize struct {
+	min, max uint64
+	toEOF    bool
+}
+
+// valid returns true when the atom size is in range
+func (a atomSize) valid(size uint64) bool {
+	if size == 0 {
+		return a.toEOF
+	}
+	return size >= a.min && (a.max == 0 || size <= a.max)
+}
+
+// detectISOBMFF returns the media type of an ISOBMFF or QuickTime file from its header. The format is chosen by the
+// ftyp major brand, or else by the compatible brands when they all belong to the same format. Files with a legacy
+// QuickTime first atom are QuickTime movies. The returned bool is false when the header is not an ISOBMFF file, and
+// the media type is nil when the brands cannot be classified, e.g. files with only generic brands such as isom.
+// Ref: https://mp4ra.org/registered-types/brands
+func detectISOBMFF(header []byte) (MediaType, bool) {
+	for offset := uint64(0); offset+8 <= uint64(len(header)); {
+		size := uint64(binary.BigEndian.Uint32(header[offset:]))
+		boxType := string(header[offset+4 : offset+8])
+		headerLen := uint64(8)
+		if size == 1 {
+			if offset+16 > uint64(len(header)) {
+				return nil, false
+			}
+			size = binary.BigEndian.Uint64(header[offset+8:])
+			headerLen = 16
+		}
+		// a size of 0 extends the box to the end of the file
+		if size != 0 && size < headerLen {
+			return nil, false
+		}
+
+		legacyAtom, isLegacy := legacyQuickTimeAtoms[boxType]
+		switch {
+		case boxType == "ftyp":
+			// the major brand and minor version precede the compatible brands
+			if size < headerLen+8 || size > uint64(len(header))-offset {
+				return nil, false
+			}
+			return classifyBrands(header[offset+headerLen : offset+size]), true
+		case isLegacy:
+			if !legacyAtom.valid(size) {
+				return nil, false
+			}
+			return QTFF{}, true
+		case slices.Contains(paddingBoxes, boxType) && size != 0 && size <= uint64(len(header))-offset:
+			offset += size
+		default:
+			return nil, false
+		}
+	}
+	return nil, false
+}
+
+// classifyBrands returns the media type registered for the brands of an ftyp box, or nil when no brand is registered
+// or the compatible brands belong to different formats
+func classifyBrands(ftyp []byte) MediaType {
+	if media := brandMedia(string(ftyp[:4])); media != nil {
+		return media
+	}
+
+	// the minor version follows the major brand
+	var found MediaType
+	for i := 8; i+4 <= len(ftyp); i += 4 {
+		media := brandMedia(string(ftyp[i : i+4]))
+		if media == nil {
+			continue
+		}
+		if found != nil && found != media {
+			return nil
+		}
+		found = media
+	}
+	return found
+}
+
+// brandMedia returns the media type of the format registering the provided brand
+func brandMedia(brand string) MediaType {
+	for _, r := range registrations {
+		if slices.Contains(r.Brands, brand) {
+			return r.Media
+		}
+	}
+	return nil
+}
@@ -2,9 +2,7 @@ package mediatype
 
 // JPEG indetifies JPEG media
 // REF: https://en.wikipedia.org/wiki/JPEG
-type JPEG struct {
-	Path string
-}
+type JPEG struct{}
 
 // String implements Stringer interface
 func (t JPEG) String() string {
@@ -0,0 +1,43 @@
+package mediatype
+
+// MKV identifies Matroska media
+// REF: https://www.matroska.org/technical/basics.html
+type MKV struct{}
+
+// String implements Stringer interface
+func (t MKV) String() string {
+	return "mkv"
+}
+
+// Ext returns the file extension
+func (t MKV) Ext() string {
+	return "." + t.String()
+}
+
+// Aliases returns known file type aliases for this media type
+func (t MKV) Aliases() map[string]struct{} {
+	return map[string]struct{}{
+		t.String(): {},
+	}
+}
+
+// WebM identifies WebM media, a subset of Matroska
+// REF: https://www.webmproject.org/docs/container/
+type WebM struct{}
+
+// String implements Stringer interface
+func (t WebM) String() string {
+	return "webm"
+}
+
+// Ext returns the file extension
+func (t WebM) Ext() string {
+	return "." + t.String()
+}
+
+// Aliases returns known file type aliases for this media type
+func (t WebM) Aliases() map[string]struct{} {
+	return map[string]struct{}{
+		t.String(): {},
+	}
+}
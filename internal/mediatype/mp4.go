@@ -2,9 +2,7 @@ package mediatype
 
 // MP4 indetifies Quicktime media
 // Ref: https://en.wikipedia.org/wiki/MP4_file_format
-type MP4 struct {
-	Path string
-}
+type MP4 struct{}
 
 // String implements Stringer interface
 func (t MP4) String() string {
@@ -20,7 +18,6 @@ func (t MP4) Ext() string {
 func (t MP4) Aliases() map[string]struct{} {
 	return map[string]struct{}{
 		t.String(): {},
-		"m4a":      {},
 		"m4p":      {},
 		"m4b":      {},
 		"m4r":      {},
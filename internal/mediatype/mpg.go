@@ -0,0 +1,25 @@
+package mediatype
+
+// MPG identifies MPEG program stream media, e.g. the MOD and TOD videos of SD camcorders
+// REF: https://en.wikipedia.org/wiki/MPEG_program_stream
+type MPG struct{}
+
+// String implements Stringer interface
+func (t MPG) String() string {
+	return "mpg"
+}
+
+// Ext returns the file extension
+func (t MPG) Ext() string {
+	return "." + t.String()
+}
+
+// Aliases returns known file type aliases for this media type
+func (t MPG) Aliases() map[string]struct{} {
+	return map[string]struct{}{
+		t.String(): {},
+		"mpeg":     {},
+		"mod":      {},
+		"tod":      {},
+	}
+}
@@ -0,0 +1,24 @@
+package mediatype
+
+// MTS identifies MPEG transport stream media, e.g. the AVCHD videos of camcorders
+// REF: https://en.wikipedia.org/wiki/AVCHD
+type MTS struct{}
+
+// String implements Stringer interface
+func (t MTS) String() string {
+	return "mts"
+}
+
+// Ext returns the file extension
+func (t MTS) Ext() string {
+	return "." + t.String()
+}
+
+// Aliases returns known file type aliases for this media type
+func (t MTS) Aliases() map[string]struct{} {
+	return map[string]struct{}{
+		t.String(): {},
+		"m2ts":     {},
+		"m2t":      {},
+	}
+}
@@ -2,9 +2,7 @@ package mediatype
 
 // PNG identifies PNG media.
 // REF: https://en.wikipedia.org/wiki/PNG
-type PNG struct {
-	Path string
-}
+type PNG struct{}
 
 // String implements Stringer interface
 func (t PNG) String() string {
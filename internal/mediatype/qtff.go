@@ -2,9 +2,7 @@ package mediatype
 
 // QTFF indetifies Quicktime media
 // Ref: https://en.wikipedia.org/wiki/QuickTime_File_Format
-type QTFF struct {
-	Path string
-}
+type QTFF struct{}
 
 // String implements Stringer interface
 func (t QTFF) String() string {
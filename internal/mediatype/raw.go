@@ -0,0 +1,194 @@
+package mediatype
+
+// DNG identifies Adobe Digital Negative RAW media
+// REF: https://en.wikipedia.org/wiki/Digital_Negative
+type DNG struct{}
+
+// String implements Stringer interface
+func (t DNG) String() string {
+	return "dng"
+}
+
+// Ext returns the file extension
+func (t DNG) Ext() string {
+	return "." + t.String()
+}
+
+// Aliases returns known file type aliases for this media type
+func (t DNG) Aliases() map[string]struct{} {
+	return map[string]struct{}{
+		t.String(): {},
+	}
+}
+
+// CR2 identifies Canon RAW version 2 media
+// REF: https://exiftool.org/canon_raw.html
+type CR2 struct{}
+
+// String implements Stringer interface
+func (t CR2) String() string {
+	return "cr2"
+}
+
+// Ext returns the file extension
+func (t CR2) Ext() string {
+	return "." + t.String()
+}
+
+// Aliases returns known file type aliases for this media type
+func (t CR2) Aliases() map[string]struct{} {
+	return map[string]struct{}{
+		t.String(): {},
+	}
+}
+
+// NEF identifies Nikon Electronic Format RAW media
+// REF: https://exiftool.org/TagNames/Nikon.html
+type NEF struct{}
+
+// String implements Stringer interface
+func (t NEF) String() string {
+	return "nef"
+}
+
+// Ext returns the file extension
+func (t NEF) Ext() string {
+	return "." + t.String()
+}
+
+// Aliases returns known file type aliases for this media type
+func (t NEF) Aliases() map[string]struct{} {
+	return map[string]struct{}{
+		t.String(): {},
+		"nrw":      {},
+	}
+}
+
+// ARW identifies Sony Alpha RAW media
+// REF: https://exiftool.org/TagNames/Sony.html
+type ARW struct{}
+
+// String implements Stringer interface
+func (t ARW) String() string {
+	return "arw"
+}
+
+// Ext returns the file extension
+func (t ARW) Ext() string {
+	return "." + t.String()
+}
+
+// Aliases returns known file type aliases for this media type
+func (t ARW) Aliases() map[string]struct{} {
+	return map[string]struct{}{
+		t.String(): {},
+		"srf":      {},
+		"sr2":      {},
+	}
+}
+
+// ORF identifies Olympus RAW Format media
+// REF: https://exiftool.org/TagNames/Olympus.html
+type ORF struct{}
+
+// String implements Stringer interface
+func (t ORF) String() string {
+	return "orf"
+}
+
+// Ext returns the file extension
+func (t ORF) Ext() string {
+	return "." + t.String()
+}
+
+// Aliases returns known file type aliases for this media type
+func (t ORF) Aliases() map[string]struct{} {
+	return map[string]struct{}{
+		t.String(): {},
+	}
+}
+
+// RW2 identifies Panasonic RAW version 2 media
+// REF: https://exiftool.org/TagNames/PanasonicRaw.html
+type RW2 struct{}
+
+// String implements Stringer interface
+func (t RW2) String() string {
+	return "rw2"
+}
+
+// Ext returns the file extension
+func (t RW2) Ext() string {
+	return "." + t.String()
+}
+
+// Aliases returns known file type aliases for this media type
+func (t RW2) Aliases() map[string]struct{} {
+	return map[string]struct{}{
+		t.String(): {},
+		"rwl":      {},
+	}
+}
+
+// PEF identifies Pentax Electronic File RAW media
+// REF: https://exiftool.org/TagNames/Pentax.html
+type PEF struct{}
+
+// String implements Stringer interface
+func (t PEF) String() string {
+	return "pef"
+}
+
+// Ext returns the file extension
+func (t PEF) Ext() string {
+	return "." + t.String()
+}
+
+// Aliases returns known file type aliases for this media type
+func (t PEF) Aliases() map[string]struct{} {
+	return map[string]struct{}{
+		t.String(): {},
+	}
+}
+
+// RAF identifies Fujifilm RAW media
+// REF: https://exiftool.org/TagNames/FujiFilm.html#RAF
+type RAF struct{}
+
+// String implements Stringer interface
+func (t RAF) String() string {
+	return "raf"
+}
+
+// Ext returns the file extension
+func (t RAF) Ext() string {
+	return "." + t.String()
+}
+
+// Aliases returns known file type aliases for this media type
+func (t RAF) Aliases() map[string]struct{} {
+	return map[string]struct{}{
+		t.String(): {},
+	}
+}
+
+// CR3 identifies Canon RAW version 3 media. Unlike the other RAW formats it is an ISOBMFF container.
+// REF: https://github.com/lclevy/canon_cr3
+type CR3 struct{}
+
+// String implements Stringer interface
+func (t CR3) String() string {
+	return "cr3"
+}
+
+// Ext returns the file extension
+func (t CR3) Ext() string {
+	return "." + t.String()
+}
+
+// Aliases returns known file type aliases for this media type
+func (t CR3) Aliases() map[string]struct{} {
+	return map[string]struct{}{
+		t.String(): {},
+	}
+}
@@ -0,0 +1,116 @@
+package mediatype
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/h2non/filetype"
+)
+
+// DateExtractor reads the dates natively stored in the metadata of the media files of a format
+type DateExtractor interface {
+	// Sources returns the names of the date sources stored by the format, e.g. exif-original
+	Sources() []string
+	// GetTime returns the date of the named source from the media file in path
+	GetTime(source, path string) (time.Time, error)
+}
+
+// Comparer checks whether two media files of a format are duplicates
+type Comparer struct {
+	// Name describes how the media is compared, e.g. phash
+	Name string
+	// Compare returns true if the media files in the provided paths are duplicates
+	Compare func(ctx context.Context, a, b string) (bool, error)
+}
+
+// Registration describes how a media format is identified, dated and compared for duplicates
+type Registration struct {
+	// Media is the media type of the format. It names the format and provides its extension and aliases.
+	Media MediaType
+	// Detect matches the magic signature of the format in the header of a file. Formats without a detector are
+	// identified by the built-in matchers of filetype.
+	Detect func(header []byte) bool
+	// Brands are the ftyp brands identifying ISOBMFF and QuickTime formats. Generic brands shared by several formats,
+	// such as isom and mif1, are not registered.
+	Brands []string
+	// Dates reads the date metadata of the format. Nil for formats without native date metadata.
+	Dates DateExtractor
+	// Duplicates compares two files of the format when checking for duplicates. Checking for duplicates is not
+	// supported when its Compare is nil.
+	Duplicates Comparer
+}
+
+// registrations are the registered formats in registration order
+var registrations []Registration
+
+// Register makes a media format known. Detectors are tried in registration order before the built-in matchers of
+// filetype. An error is returned when the name or an alias of the format is already registered.
+func Register(r Registration) error {
+	if r.Media == nil {
+		return fmt.Errorf("mediatype: registration without media type")
+	}
+	for _, known := range registrations {
+		for alias := range r.Media.Aliases() {
+			if contains(known.Media.Aliases(), alias) {
+				return fmt.Errorf("mediatype: alias %q of %s is already registered by %s", alias, r.Media, known.Media)
+			}
+		}
+	}
+	registrations = append(registrations, r)
+	return nil
+}
+
+// Registrations returns the registered formats in registration order
+func Registrations() []Registration {
+	return slices.Clone(registrations)
+}
+
+// Lookup returns the registration of the provided media type
+func Lookup(media MediaType) (Registration, bool) {
+	for _, r := range registrations {
+		if r.Media == media {
+			return r, true
+		}
+	}
+	return Registration{}, false
+}
+
+// Signature returns how the format is identified by its magic signature: "custom" for formats with a detector, "ftyp"
+// for formats identified by their ftyp brands, "filetype" for formats identified by a built-in matcher of filetype, or
+// an empty string for formats that are only identified by their file extension.
+func (r Registration) Signature() string {
+	if r.Detect != nil {
+		return "custom"
+	}
+	if len(r.Brands) > 0 {
+		return "ftyp"
+	}
+	for alias := range r.Media.Aliases() {
+		if filetype.IsSupported(alias) {
+			return "filetype"
+		}
+	}
+	return ""
+}
+
+// lookupAlias returns the registration of the format with the provided file extension alias
+func lookupAlias(ext string) (Registration, bool) {
+	for _, r := range registrations {
+		if contains(r.Media.Aliases(), ext) {
+			return r, true
+		}
+	}
+	return Registration{}, false
+}
+
+// detect returns the registration of the first format whose detector matches the provided header
+func detect(header []byte) (Registration, bool) {
+	for _, r := range registrations {
+		if r.Detect != nil && r.Detect(header) {
+			return r, true
+		}
+	}
+	return Registration{}, false
+}
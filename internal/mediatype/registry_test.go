@@ -0,0 +1,156 @@
+package mediatype
+
+import (
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useRegistrations replaces the registered formats for the duration of the test
+func useRegistrations(t *testing.T, regs ...Registration) {
+	saved := registrations
+	registrations = nil
+	t.Cleanup(func() {
+		registrations = saved
+	})
+	for _, r := range regs {
+		require.NoError(t, Register(r))
+	}
+}
+
+func TestRegister(t *testing.T) {
+	useRegistrations(t,
+		Registration{Media: JPEG{}},
+		Registration{Media: PNG{}},
+		Registration{Media: TIFF{}},
+	)
+
+	t.Run("registration order", func(t *testing.T) {
+		assert.Equal(t, []MediaType{JPEG{}, PNG{}, TIFF{}}, AllKnownMediaTypes())
+	})
+
+	t.Run("lookup", func(t *testing.T) {
+		r, ok := Lookup(PNG{})
+		require.True(t, ok)
+		assert.Equal(t, PNG{}, r.Media)
+
+		_, ok = Lookup(Unknown{})
+		assert.False(t, ok)
+	})
+
+	t.Run("duplicate alias", func(t *testing.T) {
+		assert.Error(t, Register(Registration{Media: JPEG{}}))
+		assert.Len(t, Registrations(), 3)
+	})
+
+	t.Run("missing media type", func(t *testing.T) {
+		assert.Error(t, Register(Registration{}))
+	})
+}
+
+func TestNewFormatDetect(t *testing.T) {
+	useRegistrations(t,
+		Registration{Media: JPEG{}},
+		Registration{Media: MTS{}, Detect: func(header []byte) bool { return len(header) > 0 && header[0] == 'G' }},
+		Registration{Media: MPG{}, Detect: func(header []byte) bool { return len(header) > 0 }},
+	)
+
+	for name, tc := range map[string]struct {
+		data     string
+		expected MediaType
+	}{
+		"first detector wins": {"G", MTS{}},
+		"later detector":      {"M", MPG{}},
+		"empty file":          {"", Unknown{}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "media.jpg")
+			require.NoError(t, os.WriteFile(path, []byte(tc.data), 0644))
+
+			f, err := NewFormat(path, true)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, f.MediaType())
+		})
+	}
+}
+
+func TestClassifyBrands(t *testing.T) {
+	useRegistrations(t,
+		Registration{Media: HEIF{}, Brands: []string{"heic"}},
+		Registration{Media: AVIF{}, Brands: []string{"avif"}},
+	)
+	ftyp := func(major string, compatible ...string) []byte {
+		data := append([]byte(major), 0, 0, 0, 0)
+		for _, c := range compatible {
+			data = append(data, c...)
+		}
+		return data
+	}
+
+	assert.Equal(t, AVIF{}, classifyBrands(ftyp("avif", "mif1")))
+	assert.Equal(t, HEIF{}, classifyBrands(ftyp("mif1", "mif1", "heic")))
+	assert.Nil(t, classifyBrands(ftyp("mif1", "mif1", "heic", "avif")))
+	assert.Nil(t, classifyBrands(ftyp("mif1", "mif1", "miaf")))
+}
+
+func TestDetectISOBMFF(t *testing.T) {
+	useRegistrations(t, Registration{Media: QTFF{}, Brands: []string{"qt  "}})
+	box := func(boxType string, payload ...byte) []byte {
+		return append(binary.BigEndian.AppendUint32(nil, uint32(8+len(payload))), append([]byte(boxType), payload...)...)
+	}
+
+	media, ok := detectISOBMFF(box("ftyp", []byte("qt  \x00\x00\x00\x00qt  ")...))
+	assert.True(t, ok)
+	assert.Equal(t, QTFF{}, media)
+
+	_, ok = detectISOBMFF([]byte("\xff\xd8\xff\xe0\x00\x10JFIF\x00"))
+	assert.False(t, ok)
+
+	for name, tc := range map[string]struct {
+		header   []byte
+		expected bool
+	}{
+		"moov":            {box("moov", box("mvhd")...), true},
+		"mdat to eof":     {append([]byte{0, 0, 0, 0}, "mdat"...), true},
+		"wide":            {box("wide"), true},
+		"empty moov":      {box("moov"), false},
+		"moov to eof":     {append([]byte{0, 0, 0, 0}, "moov"...), false},
+		"oversized wide":  {box("wide", []byte("data")...), false},
+		"undersized pnot": {box("pnot"), false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, ok := detectISOBMFF(tc.header)
+			assert.Equal(t, tc.expected, ok)
+		})
+	}
+}
+
+func TestAccept(t *testing.T) {
+	useRegistrations(t, Registration{Media: JPEG{}})
+	ctx := context.Background()
+
+	f, err := NewFormat("image.jpeg", false)
+	require.NoError(t, err)
+	v := FormatWithVisitor[string](f)
+	actual, err := v.Accept(ctx, mediaName{})
+	require.NoError(t, err)
+	assert.Equal(t, "jpg image.jpeg", actual)
+
+	f, err = NewFormat("notes.txt", false)
+	require.NoError(t, err)
+	v = FormatWithVisitor[string](f)
+	_, err = v.Accept(ctx, mediaName{})
+	assert.Error(t, err)
+}
+
+// mediaName is a visitor returning the format name and path of the media
+type mediaName struct{}
+
+func (mediaName) Visit(_ context.Context, format Registration, path string) (string, error) {
+	return format.Media.String() + " " + path, nil
+}
@@ -2,9 +2,7 @@ package mediatype
 
 // TIFF indetifies TIFF media
 // REF: https://en.wikipedia.org/wiki/TIFF
-type TIFF struct {
-	Path string
-}
+type TIFF struct{}
 
 // String implements Stringer interface
 func (t TIFF) String() string {
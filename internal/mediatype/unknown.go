@@ -1,9 +1,7 @@
 package mediatype
 
 // Unknown identifies an unknown media type
-type Unknown struct {
-	Path string
-}
+type Unknown struct{}
 
 // String implements Stringer interface
 func (t Unknown) String() string {
@@ -0,0 +1,22 @@
+package mediatype
+
+// WebP identifies WebP media.
+// REF: https://developers.google.com/speed/webp/docs/riff_container
+type WebP struct{}
+
+// String implements Stringer interface
+func (t WebP) String() string {
+	return "webp"
+}
+
+// Ext returns the file extension
+func (t WebP) Ext() string {
+	return "." + t.String()
+}
+
+// Aliases returns known file type aliases for this media type
+func (t WebP) Aliases() map[string]struct{} {
+	return map[string]struct{}{
+		t.String(): {},
+	}
+}
@@ -0,0 +1,157 @@
+package mediaverify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dtrejod/goexif/internal/ilog"
+	"github.com/dtrejod/goexif/internal/mediatype"
+	"go.uber.org/zap"
+)
+
+// Mismatch is a media file whose extension names a different media type than its magic signature, or whose magic
+// signature could not be classified so its media type could not be verified
+type Mismatch struct {
+	// Path is the path of the file
+	Path string `json:"path"`
+	// ExtType is the media type named by the file extension
+	ExtType string `json:"extType"`
+	// DetectedType is the media type identified by the magic signature
+	DetectedType string `json:"detectedType"`
+	// Confidence is how certain the detected media type is. Low confidence media types are taken from the file
+	// extension, so the file is not renamed.
+	Confidence string `json:"confidence"`
+	// FixedPath is the path the file was renamed to. Empty when the file was not renamed.
+	FixedPath string `json:"fixedPath,omitempty"`
+
+	detected mediatype.MediaType
+}
+
+// FixError is returned by Verify together with the mismatches when renaming any of them failed
+type FixError struct {
+	// Paths are the paths of the files that could not be renamed
+	Paths []string
+	// Err joins the rename errors
+	Err error
+}
+
+// Error implements the error interface
+func (e *FixError) Error() string {
+	return fmt.Sprintf("failed to rename %d files: %v", len(e.Paths), e.Err)
+}
+
+// Unwrap returns the rename errors
+func (e *FixError) Unwrap() error {
+	return e.Err
+}
+
+// Verify walks the directory and returns the media files whose extension does not match their magic signature. Only
+// files with a known media extension are verified, and files whose magic signature cannot be identified are left out.
+// Files whose magic signature cannot be classified, e.g. ISOBMFF files with only generic brands, are returned with low
+// confidence. With fix, the high confidence mismatches are renamed in place to the extension of the detected media
+// type. See Fix. All mismatches are renamed even when one fails, and then a FixError is returned with the mismatches.
+func Verify(ctx context.Context, dir string, fix bool) ([]Mismatch, error) {
+	var mismatches []Mismatch
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		logger := ilog.FromContext(ctx).With(zap.String("path", path))
+
+		extMedia, err := mediatype.NewFormat(path, false)
+		if err != nil {
+			return err
+		}
+		if _, ok := extMedia.MediaType().(mediatype.Unknown); ok {
+			return nil
+		}
+
+		detectedMedia, err := mediatype.NewFormat(path, true)
+		if err != nil {
+			logger.Debug("Could not identify file by its magic signature.", zap.Error(err))
+			return nil
+		}
+		if _, ok := detectedMedia.MediaType().(mediatype.Unknown); ok {
+			logger.Debug("Could not identify file by its magic signature.")
+			return nil
+		}
+		if detectedMedia.Confidence() == mediatype.LowConfidence {
+			logger.Debug("Could not classify file by its magic signature.")
+		} else if mediatype.EqualFormats(extMedia, detectedMedia) {
+			return nil
+		}
+
+		mismatches = append(mismatches, Mismatch{
+			Path:         path,
+			ExtType:      extMedia.MediaType().String(),
+			DetectedType: detectedMedia.MediaType().String(),
+			Confidence:   detectedMedia.Confidence().String(),
+			detected:     detectedMedia.MediaType(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// files are renamed after the walk so renamed files are not visited again
+	if !fix {
+		return mismatches, nil
+	}
+	var fixErr FixError
+	for i := range mismatches {
+		if mismatches[i].Confidence == mediatype.LowConfidence.String() {
+			continue
+		}
+		if err := mismatches[i].Fix(ctx); err != nil {
+			ilog.FromContext(ctx).Warn("Failed to rename file.", zap.String("path", mismatches[i].Path), zap.Error(err))
+			fixErr.Paths = append(fixErr.Paths, mismatches[i].Path)
+			fixErr.Err = errors.Join(fixErr.Err, err)
+		}
+	}
+	if fixErr.Err != nil {
+		return mismatches, &fixErr
+	}
+	return mismatches, nil
+}
+
+// Fix renames the file in place to the extension of the detected media type. When the name is taken, a counter is
+// appended to the base name, e.g. ispng.jpg is renamed to ispng_1.png when ispng.png exists.
+func (m *Mismatch) Fix(ctx context.Context) error {
+	outPath, err := freePath(m.Path, m.detected.Ext())
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(m.Path, outPath); err != nil {
+		return err
+	}
+	ilog.FromContext(ctx).Info("Renamed file to its detected media type.",
+		zap.String("path", m.Path),
+		zap.String("fixedPath", outPath))
+	m.FixedPath = outPath
+	return nil
+}
+
+// freePath returns the path with the provided extension that does not exist yet
+func freePath(path, ext string) (string, error) {
+	base := strings.TrimSuffix(path, filepath.Ext(path))
+	outPath := base + ext
+	for i := 1; ; i++ {
+		_, err := os.Lstat(outPath)
+		if errors.Is(err, os.ErrNotExist) {
+			return outPath, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		outPath = fmt.Sprintf("%s_%d%s", base, i, ext)
+	}
+}
@@ -0,0 +1,103 @@
+package mediaverify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dtrejod/goexif/internal/mediaformats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	if err := mediaformats.Register(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
+
+func TestVerify(t *testing.T) {
+	ctx := context.Background()
+	img := image.NewGray(image.Rect(0, 0, 8, 8))
+	var pngData, jpegData bytes.Buffer
+	require.NoError(t, png.Encode(&pngData, img))
+	require.NoError(t, jpeg.Encode(&jpegData, img, nil))
+	// an ftyp box with only the generic isom brand cannot be classified, so it keeps the type of its extension
+	genericMP4 := []byte("\x00\x00\x00\x14ftypisom\x00\x00\x02\x00isom")
+
+	setup := func(t *testing.T) string {
+		dir := t.TempDir()
+		for name, data := range map[string][]byte{
+			"ispng.jpg":      pngData.Bytes(),
+			"ispng.png":      pngData.Bytes(),
+			"isjpeg.jpg":     jpegData.Bytes(),
+			"clip.mov":       genericMP4,
+			"notes.txt":      pngData.Bytes(),
+			"sub/ispng.jpeg": pngData.Bytes(),
+		} {
+			path := filepath.Join(dir, name)
+			require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+			require.NoError(t, os.WriteFile(path, data, 0644))
+		}
+		return dir
+	}
+
+	t.Run("report", func(t *testing.T) {
+		dir := setup(t)
+		mismatches, err := Verify(ctx, dir, false)
+		require.NoError(t, err)
+		require.Len(t, mismatches, 3)
+		assert.Equal(t, filepath.Join(dir, "clip.mov"), mismatches[0].Path)
+		assert.Equal(t, "mov", mismatches[0].DetectedType)
+		assert.Equal(t, "low", mismatches[0].Confidence)
+		assert.Equal(t, filepath.Join(dir, "ispng.jpg"), mismatches[1].Path)
+		assert.Equal(t, "jpg", mismatches[1].ExtType)
+		assert.Equal(t, "png", mismatches[1].DetectedType)
+		assert.Equal(t, "high", mismatches[1].Confidence)
+		assert.Empty(t, mismatches[1].FixedPath)
+		assert.Equal(t, filepath.Join(dir, "sub", "ispng.jpeg"), mismatches[2].Path)
+		assert.FileExists(t, filepath.Join(dir, "ispng.jpg"))
+	})
+
+	t.Run("fix", func(t *testing.T) {
+		dir := setup(t)
+		mismatches, err := Verify(ctx, dir, true)
+		require.NoError(t, err)
+		require.Len(t, mismatches, 3)
+		// low confidence media types are guessed, so the file is not renamed
+		assert.Empty(t, mismatches[0].FixedPath)
+		// ispng.png is taken
+		assert.Equal(t, filepath.Join(dir, "ispng_1.png"), mismatches[1].FixedPath)
+		assert.Equal(t, filepath.Join(dir, "sub", "ispng.png"), mismatches[2].FixedPath)
+		assert.NoFileExists(t, filepath.Join(dir, "ispng.jpg"))
+		assert.FileExists(t, filepath.Join(dir, "ispng_1.png"))
+		assert.FileExists(t, filepath.Join(dir, "sub", "ispng.png"))
+	})
+
+	t.Run("fix failure", func(t *testing.T) {
+		dir := setup(t)
+		// the counter pushes the free name past the maximum file name length
+		long := strings.Repeat("a", 251)
+		for _, name := range []string{long + ".jpg", long + ".png"} {
+			require.NoError(t, os.WriteFile(filepath.Join(dir, name), pngData.Bytes(), 0644))
+		}
+
+		mismatches, err := Verify(ctx, dir, true)
+		var fixErr *FixError
+		require.ErrorAs(t, err, &fixErr)
+		assert.Equal(t, []string{filepath.Join(dir, long+".jpg")}, fixErr.Paths)
+		require.Len(t, mismatches, 4)
+		assert.Empty(t, mismatches[0].FixedPath)
+		// the other mismatches are still renamed
+		assert.Equal(t, filepath.Join(dir, "ispng_1.png"), mismatches[2].FixedPath)
+	})
+}
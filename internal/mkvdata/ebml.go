@@ -0,0 +1,116 @@
+package mkvdata
+
+import (
+	"errors"
+	"io"
+	"math/bits"
+)
+
+const (
+	// maxIDLength and maxSizeLength are the maximum lengths of the variable length integers of an element header
+	// Ref: https://www.rfc-editor.org/rfc/rfc8794#section-4
+	maxIDLength   = 4
+	maxSizeLength = 8
+	// unknownSize is the size of master elements written without a known size, e.g. by live recorders
+	unknownSize = -1
+)
+
+// element is the header of an EBML element
+type element struct {
+	id   uint32
+	size int64
+	// headerLen is the number of bytes of the ID and size
+	headerLen int64
+}
+
+// ebmlReader reads EBML element headers from a stream. Element data that is not needed is skipped by seeking, so only
+// the headers and the data that is read are loaded.
+type ebmlReader struct {
+	r io.ReadSeeker
+}
+
+func newEBMLReader(r io.ReadSeeker) *ebmlReader {
+	return &ebmlReader{r: r}
+}
+
+// next returns the header of the next element
+func (e *ebmlReader) next() (element, error) {
+	id, idLen, err := e.readVint(maxIDLength)
+	if err != nil {
+		return element{}, err
+	}
+	// IDs keep their length marker
+	id |= 1 << (7 * idLen)
+
+	size, sizeLen, err := e.readVint(maxSizeLength)
+	if err != nil {
+		return element{}, noEOF(err)
+	}
+	el := element{id: uint32(id), size: int64(size), headerLen: int64(idLen + sizeLen)}
+	// a size with all value bits set is reserved for an unknown size
+	if size == 1<<(7*sizeLen)-1 {
+		el.size = unknownSize
+	}
+	return el, nil
+}
+
+// readVint reads a variable length integer of at most maxLen bytes. The number of leading zero bits of the first byte
+// is the number of bytes that follow it. The value is returned without the length marker.
+func (e *ebmlReader) readVint(maxLen int) (uint64, int, error) {
+	var buf [maxSizeLength]byte
+	if _, err := io.ReadFull(e.r, buf[:1]); err != nil {
+		return 0, 0, err
+	}
+	length := bits.LeadingZeros8(buf[0]) + 1
+	if length > maxLen {
+		return 0, 0, errors.New("invalid EBML variable length integer")
+	}
+	if _, err := io.ReadFull(e.r, buf[1:length]); err != nil {
+		return 0, 0, noEOF(err)
+	}
+
+	value := uint64(buf[0]) & (0xff >> length)
+	for _, b := range buf[1:length] {
+		value = value<<8 | uint64(b)
+	}
+	return value, length, nil
+}
+
+// skip skips the data of the element
+func (e *ebmlReader) skip(el element) error {
+	if el.size == unknownSize {
+		return errors.New("cannot skip EBML element of unknown size")
+	}
+	_, err := e.r.Seek(el.size, io.SeekCurrent)
+	return err
+}
+
+// readInt reads the data of a signed integer element
+func (e *ebmlReader) readInt(el element) (int64, error) {
+	if el.size < 0 || el.size > 8 {
+		return 0, errors.New("invalid EBML signed integer size")
+	}
+	buf := make([]byte, el.size)
+	if _, err := io.ReadFull(e.r, buf); err != nil {
+		return 0, noEOF(err)
+	}
+
+	var value int64
+	for i, b := range buf {
+		// sign extend from the first byte
+		if i == 0 {
+			value = int64(int8(b))
+			continue
+		}
+		value = value<<8 | int64(b)
+	}
+	return value, nil
+}
+
+// noEOF returns io.ErrUnexpectedEOF for an element that was cut short
+func noEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
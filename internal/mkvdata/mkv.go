@@ -0,0 +1,120 @@
+package mkvdata
+
+import (
+	"errors"
+	"io"
+	"os"
+	"time"
+)
+
+// Element IDs of the Matroska header section
+// Ref: https://www.matroska.org/technical/elements.html
+const (
+	ebmlID    = 0x1a45dfa3
+	segmentID = 0x18538067
+	infoID    = 0x1549a966
+	dateUTCID = 0x4461
+	clusterID = 0x1f43b675
+)
+
+var (
+	// dateUTCEpoch is the time DateUTC counts nanoseconds from
+	dateUTCEpoch = time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	errDateNotFound = errors.New("could not find DateUTC in Matroska metadata")
+)
+
+// GetTime returns the Segment/Info/DateUTC from the Matroska or WebM media referenced in the provided path. It is the
+// date the media was muxed in UTC. Only the header section is read, so the search stops at the first Cluster.
+func GetTime(path string) (time.Time, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer f.Close()
+
+	return getTime(newEBMLReader(f))
+}
+
+func getTime(r *ebmlReader) (time.Time, error) {
+	el, err := r.next()
+	if err != nil {
+		return time.Time{}, noEOF(err)
+	}
+	if el.id != ebmlID {
+		return time.Time{}, errors.New("missing EBML header")
+	}
+	if err := r.skip(el); err != nil {
+		return time.Time{}, err
+	}
+
+	// top level elements other than the Segment, such as Void, are skipped
+	for {
+		el, err := r.next()
+		if err == io.EOF {
+			return time.Time{}, errDateNotFound
+		}
+		if err != nil {
+			return time.Time{}, err
+		}
+		if el.id == segmentID {
+			break
+		}
+		if err := r.skip(el); err != nil {
+			return time.Time{}, err
+		}
+	}
+
+	for {
+		el, err := r.next()
+		if err == io.EOF {
+			return time.Time{}, errDateNotFound
+		}
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		switch el.id {
+		case infoID:
+			return getTimeFromInfo(r, el)
+		case clusterID:
+			// the media data follows the header section
+			return time.Time{}, errDateNotFound
+		default:
+			if err := r.skip(el); err != nil {
+				return time.Time{}, err
+			}
+		}
+	}
+}
+
+// getTimeFromInfo returns the DateUTC from the children of the Info element
+func getTimeFromInfo(r *ebmlReader, info element) (time.Time, error) {
+	if info.size == unknownSize {
+		return time.Time{}, errors.New("invalid Matroska Info element size")
+	}
+
+	for remaining := info.size; remaining > 0; {
+		el, err := r.next()
+		if err != nil {
+			return time.Time{}, noEOF(err)
+		}
+		if el.size == unknownSize {
+			return time.Time{}, errors.New("invalid Matroska Info child element size")
+		}
+		remaining -= el.headerLen + el.size
+
+		if el.id != dateUTCID {
+			if err := r.skip(el); err != nil {
+				return time.Time{}, err
+			}
+			continue
+		}
+		ns, err := r.readInt(el)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return dateUTCEpoch.Add(time.Duration(ns)), nil
+	}
+	return time.Time{}, errDateNotFound
+}
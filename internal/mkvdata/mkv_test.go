@@ -0,0 +1,102 @@
+package mkvdata
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ebml returns an encoded EBML element with the provided ID and data. The size is written with 8 bytes.
+func ebml(id uint32, data ...[]byte) []byte {
+	var payload []byte
+	for _, d := range data {
+		payload = append(payload, d...)
+	}
+	out := binary.BigEndian.AppendUint32(nil, id)
+	for out[0] == 0 {
+		out = out[1:]
+	}
+	out = binary.BigEndian.AppendUint64(out, 1<<56|uint64(len(payload)))
+	return append(out, payload...)
+}
+
+// unknownSizeEBML returns an encoded EBML element of unknown size
+func unknownSizeEBML(id uint32, data ...[]byte) []byte {
+	out := binary.BigEndian.AppendUint32(nil, id)
+	out = append(out, 0xff)
+	for _, d := range data {
+		out = append(out, d...)
+	}
+	return out
+}
+
+func dateUTC(t time.Time) []byte {
+	return ebml(dateUTCID, binary.BigEndian.AppendUint64(nil, uint64(t.Sub(dateUTCEpoch))))
+}
+
+func writeMKV(t *testing.T, data ...[]byte) string {
+	t.Helper()
+	var out []byte
+	for _, d := range data {
+		out = append(out, d...)
+	}
+	path := filepath.Join(t.TempDir(), "video.mkv")
+	require.NoError(t, os.WriteFile(path, out, 0644))
+	return path
+}
+
+func TestGetTime(t *testing.T) {
+	expected := time.Date(2023, 10, 1, 12, 30, 0, 0, time.UTC)
+	header := ebml(ebmlID, ebml(0x4282, []byte("webm")))
+	seekHead := ebml(0x114d9b74, make([]byte, 32))
+	timestampScale := ebml(0x2ad7b1, []byte{0x0f, 0x42, 0x40})
+	cluster := ebml(clusterID, make([]byte, 64))
+
+	t.Run("date utc", func(t *testing.T) {
+		actual, err := GetTime(writeMKV(t, header,
+			ebml(segmentID, seekHead, ebml(infoID, timestampScale, dateUTC(expected)), cluster)))
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("date before 2001", func(t *testing.T) {
+		before := time.Date(1999, 12, 31, 23, 0, 0, 0, time.UTC)
+		actual, err := GetTime(writeMKV(t, header, ebml(segmentID, ebml(infoID, dateUTC(before)))))
+		require.NoError(t, err)
+		assert.Equal(t, before, actual)
+	})
+
+	t.Run("unknown segment size", func(t *testing.T) {
+		actual, err := GetTime(writeMKV(t, header,
+			unknownSizeEBML(segmentID, ebml(infoID, timestampScale, dateUTC(expected)), unknownSizeEBML(clusterID))))
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("info after cluster is not read", func(t *testing.T) {
+		_, err := GetTime(writeMKV(t, header,
+			ebml(segmentID, seekHead, cluster, ebml(infoID, dateUTC(expected)))))
+		assert.ErrorIs(t, err, errDateNotFound)
+	})
+
+	t.Run("without date utc", func(t *testing.T) {
+		_, err := GetTime(writeMKV(t, header, ebml(segmentID, ebml(infoID, timestampScale), cluster)))
+		assert.ErrorIs(t, err, errDateNotFound)
+	})
+
+	t.Run("not ebml", func(t *testing.T) {
+		_, err := GetTime(writeMKV(t, []byte("RIFF\x00\x00\x00\x00AVI ")))
+		assert.Error(t, err)
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		data := ebml(segmentID, ebml(infoID, dateUTC(expected)))
+		_, err := GetTime(writeMKV(t, header, data[:len(data)-4]))
+		assert.Error(t, err)
+	})
+}
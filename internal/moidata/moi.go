@@ -0,0 +1,78 @@
+package moidata
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dtrejod/goexif/internal/exifdata"
+)
+
+const (
+	// dateOffset is the offset of the recording date in the MOI header. The date is a big-endian year, the month, day,
+	// hour and minute, and the big-endian milliseconds of the minute.
+	// Ref: https://exiftool.org/TagNames/MOI.html
+	dateOffset = 0x06
+	dateLength = 8
+)
+
+// moiVersion starts the header of every MOI file
+var moiVersion = []byte("V6")
+
+// GetTime returns the recording date from the MOI sidecar of the MPEG-PS media referenced in the provided path. JVC,
+// Panasonic and Canon camcorders record MOD and TOD videos without metadata and write the date into the sidecar
+// instead. The date is in exifdata.UnknownLocation since the timezone is not recorded.
+func GetTime(path string) (time.Time, error) {
+	sidecar, ok := SidecarPath(path)
+	if !ok {
+		return time.Time{}, errors.New("could not find MOI sidecar")
+	}
+
+	f, err := os.Open(sidecar)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer f.Close()
+
+	header := make([]byte, dateOffset+dateLength)
+	if _, err := io.ReadFull(f, header); err != nil {
+		return time.Time{}, err
+	}
+	return parseHeader(header)
+}
+
+// SidecarPath returns the path of the MOI sidecar of the media referenced in the provided path. The sidecar shares the
+// base name of the media. False is returned when there is no sidecar.
+func SidecarPath(path string) (string, bool) {
+	base := strings.TrimSuffix(path, filepath.Ext(path))
+	for _, candidate := range []string{base + ".MOI", base + ".moi"} {
+		if info, err := os.Stat(candidate); err == nil && info.Mode().IsRegular() {
+			return candidate, true
+		}
+	}
+	return "", false
+}
+
+func parseHeader(header []byte) (time.Time, error) {
+	if !bytes.HasPrefix(header, moiVersion) {
+		return time.Time{}, errors.New("unknown MOI version")
+	}
+
+	date := header[dateOffset : dateOffset+dateLength]
+	year := int(binary.BigEndian.Uint16(date))
+	month, day, hour, minute := int(date[2]), int(date[3]), int(date[4]), int(date[5])
+	ms := time.Duration(binary.BigEndian.Uint16(date[6:])) * time.Millisecond
+
+	t := time.Date(year, time.Month(month), day, hour, minute, 0, 0, exifdata.UnknownLocation)
+	if t.Month() != time.Month(month) || t.Day() != day || t.Hour() != hour || t.Minute() != minute ||
+		ms >= time.Minute {
+		return time.Time{}, fmt.Errorf("invalid MOI date: % x", date)
+	}
+	return t.Add(ms), nil
+}
@@ -0,0 +1,67 @@
+package moidata
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dtrejod/goexif/internal/exifdata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// moi returns a MOI header with the provided recording date
+func moi(year uint16, month, day, hour, minute byte, ms uint16) []byte {
+	header := append([]byte("V6"), make([]byte, 4)...)
+	header = binary.BigEndian.AppendUint16(header, year)
+	header = append(header, month, day, hour, minute)
+	header = binary.BigEndian.AppendUint16(header, ms)
+	// the duration and stream info follow the date
+	return append(header, make([]byte, 0x100)...)
+}
+
+func TestGetTime(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		moiName  string
+		moi      []byte
+		expected time.Time
+		wantErr  bool
+	}{
+		{
+			name:     "upper case sidecar",
+			moiName:  "MOV001.MOI",
+			moi:      moi(2008, 7, 4, 18, 30, 15250),
+			expected: time.Date(2008, 7, 4, 18, 30, 15, 250*int(time.Millisecond), exifdata.UnknownLocation),
+		},
+		{
+			name:     "lower case sidecar",
+			moiName:  "MOV001.moi",
+			moi:      moi(2008, 7, 4, 18, 30, 0),
+			expected: time.Date(2008, 7, 4, 18, 30, 0, 0, exifdata.UnknownLocation),
+		},
+		{name: "without sidecar", moiName: "MOV002.MOI", moi: moi(2008, 7, 4, 18, 30, 0), wantErr: true},
+		{name: "invalid date", moiName: "MOV001.MOI", moi: moi(2008, 13, 4, 18, 30, 0), wantErr: true},
+		{name: "invalid milliseconds", moiName: "MOV001.MOI", moi: moi(2008, 7, 4, 18, 30, 60000), wantErr: true},
+		{name: "unknown version", moiName: "MOV001.MOI", moi: append([]byte("V5"), moi(2008, 7, 4, 18, 30, 0)[2:]...),
+			wantErr: true},
+		{name: "truncated", moiName: "MOV001.MOI", moi: []byte("V6"), wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "MOV001.MOD")
+			require.NoError(t, os.WriteFile(path, []byte{0x00, 0x00, 0x01, 0xba}, 0644))
+			require.NoError(t, os.WriteFile(filepath.Join(dir, tc.moiName), tc.moi, 0644))
+
+			actual, err := GetTime(path)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package moovdata
+
+import (
+	"bytes"
+	"errors"
+	"os"
+
+	mp4 "github.com/abema/go-mp4"
+	"github.com/dtrejod/goexif/internal/exifdata"
+)
+
+var (
+	// canonUUID is the user type of the moov/uuid box holding the metadata of Canon CR3 files
+	// Ref: https://github.com/lclevy/canon_cr3
+	canonUUID = []byte{0x85, 0xc0, 0xb6, 0x87, 0x82, 0x0f, 0x11, 0xe0, 0x81, 0x11, 0xf4, 0xce, 0x46, 0x2b, 0x6a, 0x48}
+
+	// cmt1BoxType holds the tags of IFD0, cmt2BoxType the tags of IFD/Exif and cmt4BoxType the tags of IFD/GPSInfo
+	// as TIFF blocks
+	cmt1BoxType = mp4.StrToBoxType("CMT1")
+	cmt2BoxType = mp4.StrToBoxType("CMT2")
+	cmt4BoxType = mp4.StrToBoxType("CMT4")
+	// thmbBoxType holds the JPEG thumbnail
+	thmbBoxType = mp4.StrToBoxType("THMB")
+)
+
+// CR3Metadata is the metadata Canon CR3 files store in their moov/uuid box
+type CR3Metadata struct {
+	// TIFFBlocks are the CMT1, CMT2 and CMT4 TIFF blocks
+	exifdata.TIFFBlocks
+	// Thumbnail is the JPEG thumbnail of the THMB box
+	Thumbnail []byte
+}
+
+// GetCR3Metadata returns the metadata from the Canon moov/uuid box of a CR3 file. The EXIF tags of CR3 files are not
+// stored in an EXIF block, instead IFD0, IFD/Exif, the MakerNote and IFD/GPSInfo are each stored as a TIFF block in the
+// CMT1 to CMT4 boxes.
+func GetCR3Metadata(path string) (CR3Metadata, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return CR3Metadata{}, err
+	}
+	defer f.Close()
+
+	var uuid []byte
+	_, err = mp4.ReadBoxStructure(f, func(h *mp4.ReadHandle) (interface{}, error) {
+		switch {
+		case isPath(h.Path, "moov"):
+			return h.Expand()
+		case isPath(h.Path, "moov", "uuid"):
+			buf := bytes.NewBuffer(make([]byte, 0, h.BoxInfo.Size))
+			if _, err := h.ReadData(buf); err != nil {
+				return nil, err
+			}
+			if bytes.HasPrefix(buf.Bytes(), canonUUID) {
+				uuid = buf.Bytes()[len(canonUUID):]
+			}
+		}
+		return nil, nil
+	})
+	if err != nil {
+		return CR3Metadata{}, err
+	}
+	if uuid == nil {
+		return CR3Metadata{}, errors.New("could not find canon uuid box")
+	}
+	return getCR3MetadataFromBoxes(uuid)
+}
+
+// getCR3MetadataFromBoxes returns the metadata from the boxes contained by the Canon uuid box
+func getCR3MetadataFromBoxes(data []byte) (CR3Metadata, error) {
+	var md CR3Metadata
+	_, err := mp4.ReadBoxStructure(bytes.NewReader(data), func(h *mp4.ReadHandle) (interface{}, error) {
+		if len(h.Path) != 1 {
+			return nil, nil
+		}
+		var value *[]byte
+		switch h.Path[0] {
+		case cmt1BoxType:
+			value = &md.IFD0
+		case cmt2BoxType:
+			value = &md.Exif
+		case cmt4BoxType:
+			value = &md.GPS
+		case thmbBoxType:
+			value = &md.Thumbnail
+		default:
+			return nil, nil
+		}
+
+		buf := bytes.NewBuffer(make([]byte, 0, h.BoxInfo.Size))
+		if _, err := h.ReadData(buf); err != nil {
+			return nil, err
+		}
+		*value = buf.Bytes()
+		return nil, nil
+	})
+	if err != nil {
+		return CR3Metadata{}, err
+	}
+
+	// the thumbnail is preceded by its dimensions and size
+	if i := bytes.Index(md.Thumbnail, []byte{0xff, 0xd8, 0xff}); i >= 0 {
+		md.Thumbnail = md.Thumbnail[i:]
+	} else {
+		md.Thumbnail = nil
+	}
+
+	if md.IFD0 == nil && md.Exif == nil {
+		return CR3Metadata{}, errors.New("could not find canon cmt boxes")
+	}
+	return md, nil
+}
@@ -0,0 +1,67 @@
+package moovdata
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	exifcommon "github.com/dsoprea/go-exif/v3/common"
+	"github.com/dtrejod/goexif/internal/exifdata"
+	"github.com/dtrejod/goexif/internal/exifdata/exiftest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCR3Metadata(t *testing.T) {
+	var thumbnail bytes.Buffer
+	require.NoError(t, jpeg.Encode(&thumbnail, image.NewGray(image.Rect(0, 0, 8, 8)), nil))
+
+	cmt1 := exiftest.EncodeBlock(t, exifcommon.IfdStandardIfdIdentity, map[string]interface{}{"Model": "Canon EOS R5"})
+	cmt2 := exiftest.EncodeBlock(t, exifcommon.IfdExifStandardIfdIdentity, map[string]interface{}{
+		"DateTimeOriginal": "2023:10:01 12:00:00",
+	})
+	cmt4 := exiftest.EncodeBlock(t, exifcommon.IfdGpsInfoStandardIfdIdentity, map[string]interface{}{
+		"GPSDateStamp": "2023:10:01",
+		"GPSTimeStamp": []exifcommon.Rational{
+			{Numerator: 3, Denominator: 1},
+			{Numerator: 0, Denominator: 1},
+			{Numerator: 0, Denominator: 1},
+		},
+	})
+	uuid := box("uuid", canonUUID,
+		box("CNCV", []byte("CanonCR3_001/01.09.00/00.00.00")),
+		box("CMT1", cmt1),
+		box("CMT2", cmt2),
+		box("CMT4", cmt4),
+		box("THMB", make([]byte, 16), thumbnail.Bytes()),
+	)
+	ftyp := box("ftyp", []byte("crx "), make([]byte, 4), []byte("crx isom"))
+	path := filepath.Join(t.TempDir(), "image.cr3")
+	require.NoError(t, os.WriteFile(path, append(ftyp, box("moov", uuid)...), 0644))
+
+	md, err := GetCR3Metadata(path)
+	require.NoError(t, err)
+	assert.Equal(t, cmt1, md.IFD0)
+	assert.Equal(t, cmt2, md.Exif)
+	assert.Equal(t, cmt4, md.GPS)
+	assert.Equal(t, thumbnail.Bytes(), md.Thumbnail)
+
+	actual, err := md.GetDateTimeOriginal()
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2023, 10, 1, 12, 0, 0, 0, exifdata.UnknownLocation), actual)
+
+	actual, err = md.GetGPSTime()
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2023, 10, 1, 3, 0, 0, 0, time.UTC), actual)
+
+	cam, err := md.GetCamera()
+	require.NoError(t, err)
+	assert.Equal(t, exifdata.Camera{Model: "Canon EOS R5"}, cam)
+
+	_, err = GetCR3Metadata(writeMoov(t))
+	assert.Error(t, err)
+}
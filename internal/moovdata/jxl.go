@@ -0,0 +1,59 @@
+package moovdata
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+
+	mp4 "github.com/abema/go-mp4"
+	"github.com/dtrejod/goexif/internal/exifdata"
+)
+
+const (
+	// maxJXLExifSize bounds the size of the Exif box that is read into memory
+	maxJXLExifSize = 16 << 20
+)
+
+// GetJXLExif returns the EXIF block of the JPEG XL media referenced in the provided path. The JPEG XL container stores
+// it in the Exif box, prefixed by the offset of the TIFF header. Bare codestreams have no metadata and Brotli compressed
+// brob boxes are not supported.
+// Ref: https://github.com/libjxl/libjxl/blob/main/doc/format_overview.md
+func GetJXLExif(path string) (exifdata.Block, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var exif []byte
+	_, err = mp4.ReadBoxStructure(f, func(h *mp4.ReadHandle) (interface{}, error) {
+		if exif != nil || !isPath(h.Path, "Exif") {
+			return nil, nil
+		}
+		if h.BoxInfo.Size > maxJXLExifSize {
+			return nil, errors.New("jxl exif box too large")
+		}
+		buf := bytes.NewBuffer(make([]byte, 0, h.BoxInfo.Size))
+		if _, err := h.ReadData(buf); err != nil {
+			return nil, err
+		}
+		exif = buf.Bytes()
+		return nil, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if exif == nil {
+		return nil, errors.New("could not find jxl exif box")
+	}
+
+	if len(exif) < 4 {
+		return nil, errors.New("jxl exif box too short")
+	}
+	offset := binary.BigEndian.Uint32(exif)
+	if uint64(offset) > uint64(len(exif)-4) {
+		return nil, errors.New("invalid jxl exif tiff header offset")
+	}
+	return exifdata.Block(exif[4+offset:]), nil
+}
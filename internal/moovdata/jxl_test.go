@@ -0,0 +1,58 @@
+package moovdata
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	exifcommon "github.com/dsoprea/go-exif/v3/common"
+	"github.com/dtrejod/goexif/internal/exifdata"
+	"github.com/dtrejod/goexif/internal/exifdata/exiftest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetJXLExif(t *testing.T) {
+	tiff := exiftest.EncodeBlock(t, exifcommon.IfdStandardIfdIdentity, map[string]interface{}{
+		"DateTime": "2023:10:01 12:00:00",
+	})
+	signature := box("JXL ", []byte{0x0d, 0x0a, 0x87, 0x0a})
+	ftyp := box("ftyp", []byte("jxl "), make([]byte, 4), []byte("jxl "))
+	codestream := box("jxlc", []byte{0xff, 0x0a}, make([]byte, 32))
+
+	writeJXL := func(t *testing.T, boxes ...[]byte) string {
+		var data []byte
+		for _, b := range boxes {
+			data = append(data, b...)
+		}
+		path := filepath.Join(t.TempDir(), "image.jxl")
+		require.NoError(t, os.WriteFile(path, data, 0644))
+		return path
+	}
+
+	t.Run("exif box", func(t *testing.T) {
+		block, err := GetJXLExif(writeJXL(t, signature, ftyp, box("Exif", make([]byte, 4), tiff), codestream))
+		require.NoError(t, err)
+		actual, err := block.GetDateTime()
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2023, 10, 1, 12, 0, 0, 0, exifdata.UnknownLocation), actual)
+	})
+
+	t.Run("exif box with tiff header offset", func(t *testing.T) {
+		exif := box("Exif", []byte{0, 0, 0, 6}, []byte("Exif\x00\x00"), tiff)
+		block, err := GetJXLExif(writeJXL(t, signature, ftyp, codestream, exif))
+		require.NoError(t, err)
+		assert.Equal(t, exifdata.Block(tiff), block)
+	})
+
+	t.Run("invalid tiff header offset", func(t *testing.T) {
+		_, err := GetJXLExif(writeJXL(t, signature, ftyp, box("Exif", []byte{0, 0, 1, 0}, tiff), codestream))
+		assert.Error(t, err)
+	})
+
+	t.Run("without exif box", func(t *testing.T) {
+		_, err := GetJXLExif(writeJXL(t, signature, ftyp, codestream))
+		assert.Error(t, err)
+	})
+}
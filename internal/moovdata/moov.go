@@ -1,50 +1,346 @@
 package moovdata
 
 import (
+	"bytes"
+	"encoding/binary"
 	"errors"
+	"fmt"
+	"io"
 	"os"
+	"strings"
 	"time"
 
 	mp4 "github.com/abema/go-mp4"
+	"github.com/dtrejod/goexif/internal/exifdata"
+)
+
+const (
+	// appleCreationDateKey is the mdta metadata key iOS devices use to store the local capture time with timezone
+	// Ref: https://developer.apple.com/documentation/quicktime-file-format/quicktime_metadata_keys
+	appleCreationDateKey = "com.apple.quicktime.creationdate"
+	// appleMakeKey and appleModelKey are the mdta metadata keys iOS devices use to store the camera make and model
+	appleMakeKey  = "com.apple.quicktime.make"
+	appleModelKey = "com.apple.quicktime.model"
+	// appleContentIdentifierKey is the mdta metadata key that pairs the video of a Live Photo with its image
+	appleContentIdentifierKey = "com.apple.quicktime.content.identifier"
+
+	// unixEpochSince1904 is the number of seconds between 1904-01-01 and 1970-01-01
+	unixEpochSince1904 = 2082844800
+	// maxSecondsSince1904 is the number of seconds between 1904-01-01 and 10000-01-01. Larger creation times are
+	// corrupt and would overflow a time.Duration.
+	maxSecondsSince1904 = 255485145600
 )
 
 var (
 	// mvhdBoxPath is the BoxPath to the MVHD Box that contains Creation Time metadata
 	// Ref: https://developer.apple.com/documentation/quicktime-file-format/movie_header_atom/creation_time
 	mvhdBoxPath mp4.BoxPath = []mp4.BoxType{mp4.BoxTypeMoov(), mp4.BoxTypeMvhd()}
+	// tkhdBoxPath is the BoxPath to the TKHD Box that contains the track Creation Time metadata
+	// Ref: https://developer.apple.com/documentation/quicktime-file-format/track_header_atom/creation_time
+	tkhdBoxPath mp4.BoxPath = []mp4.BoxType{mp4.BoxTypeMoov(), mp4.BoxTypeTrak(), mp4.BoxTypeTkhd()}
+	// mdhdBoxPath is the BoxPath to the MDHD Box that contains the media Creation Time metadata
+	// Ref: https://developer.apple.com/documentation/quicktime-file-format/media_header_atom/creation_time
+	mdhdBoxPath mp4.BoxPath = []mp4.BoxType{mp4.BoxTypeMoov(), mp4.BoxTypeTrak(), mp4.BoxTypeMdia(), mp4.BoxTypeMdhd()}
+
+	// dayBoxType is the user data and item list box type that contains the creation date
+	// Ref: https://developer.apple.com/documentation/quicktime-file-format/user_data_atoms
+	dayBoxType = mp4.BoxType{0xA9, 'd', 'a', 'y'}
+	// makeBoxType and modelBoxType are the user data and item list box types that contain the camera make and model
+	makeBoxType  = mp4.BoxType{0xA9, 'm', 'a', 'k'}
+	modelBoxType = mp4.BoxType{0xA9, 'm', 'o', 'd'}
+	// userDataBoxTypes are the user data and item list box types collected from the metadata
+	userDataBoxTypes = []mp4.BoxType{dayBoxType, makeBoxType, modelBoxType}
+
+	// metadataDateLayouts are the known layouts of creation dates found in QuickTime metadata items
+	metadataDateLayouts = []string{
+		"2006-01-02T15:04:05-0700",
+		"2006-01-02T15:04:05.000-0700",
+		time.RFC3339,
+		time.RFC3339Nano,
+		"20060102T150405Z0700",
+		"20060102T150405.000Z0700",
+	}
 )
 
-// GetTime return the CreationTime from a MooV file
+// GetTime return the CreationTime from a MooV file. Creation dates from the QuickTime metadata items are preferred
+// since they are recorded in local time with a timezone. Otherwise the movie header is used, falling back to the track
+// and then media headers when it is missing or unset.
 func GetTime(path string) (time.Time, error) {
-	boxes, err := getMetadataBoxes(path)
+	f, err := os.Open(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer f.Close()
+
+	md, err := getQuickTimeMetadata(f)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if t, ok := md.creationTime(); ok {
+		return t, nil
+	}
+
+	boxes, err := mp4.ExtractBoxesWithPayload(f, nil, []mp4.BoxPath{mvhdBoxPath, tkhdBoxPath, mdhdBoxPath})
 	if err != nil {
 		return time.Time{}, err
 	}
 	return getTimeFromBoxes(boxes)
 }
 
+// GetCamera returns the camera make and model from the QuickTime metadata of a MooV file. The serial number is not
+// recorded by QuickTime metadata.
+func GetCamera(path string) (exifdata.Camera, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return exifdata.Camera{}, err
+	}
+	defer f.Close()
+
+	md, err := getQuickTimeMetadata(f)
+	if err != nil {
+		return exifdata.Camera{}, err
+	}
+	cam := md.camera()
+	if cam == (exifdata.Camera{}) {
+		return exifdata.Camera{}, errors.New("could not find camera metadata")
+	}
+	return cam, nil
+}
+
+// GetContentIdentifier returns the Apple Live Photo content identifier from the QuickTime metadata of a MooV file. The
+// image of the Live Photo records the same identifier in its MakerNote.
+func GetContentIdentifier(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	md, err := getQuickTimeMetadata(f)
+	if err != nil {
+		return "", err
+	}
+	id := strings.TrimRight(strings.TrimSpace(string(md.item(appleContentIdentifierKey))), "\x00")
+	if id == "" {
+		return "", errors.New("could not find content identifier metadata")
+	}
+	return id, nil
+}
+
 func getTimeFromBoxes(boxes []*mp4.BoxInfoWithPayload) (time.Time, error) {
+	var mvhd, tkhd, mdhd uint64
 	for _, box := range boxes {
 		switch t := box.Payload.(type) {
 		case *mp4.Mvhd:
-			return timeSince1904(t.CreationTimeV0), nil
+			mvhd = firstNonZero(mvhd, t.GetCreationTime())
+		case *mp4.Tkhd:
+			tkhd = firstNonZero(tkhd, t.GetCreationTime())
+		case *mp4.Mdhd:
+			mdhd = firstNonZero(mdhd, t.GetCreationTime())
+		}
+	}
+
+	// A zero creation time means the muxer never set it, so it is treated as
+	// missing rather than as 1904-01-01
+	for _, sec := range []uint64{mvhd, tkhd, mdhd} {
+		if sec != 0 {
+			return timeSince1904(sec)
 		}
 	}
-	return time.Time{}, errors.New("could not find mvhd box from known mp4 boxes")
+	return time.Time{}, errors.New("could not find creation time from known mp4 boxes")
 }
 
-func getMetadataBoxes(path string) ([]*mp4.BoxInfoWithPayload, error) {
-	f, err := os.Open(path)
-	if err != nil {
+// quickTimeMetadata are the metadata items found in a MooV file
+type quickTimeMetadata struct {
+	// keys are the mdta key names indexed by their 1-based item index
+	keys map[uint32]string
+	// items are the item values indexed by their 1-based item index
+	items map[uint32][]byte
+	// userData are the values of the ©day, ©mak and ©mod user data or item list entries
+	userData map[mp4.BoxType][]byte
+}
+
+// creationTime returns the creation time from the metadata items. The Apple
+// creation date key is preferred over the ©day entry.
+func (md quickTimeMetadata) creationTime() (time.Time, bool) {
+	if t, err := parseMetadataDate(md.item(appleCreationDateKey)); err == nil {
+		return t, true
+	}
+	if t, err := parseMetadataDate(md.userData[dayBoxType]); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+// item returns the value of the item with the provided mdta key name
+func (md quickTimeMetadata) item(key string) []byte {
+	for index, k := range md.keys {
+		if k == key {
+			return md.items[index]
+		}
+	}
+	return nil
+}
+
+// camera returns the camera make and model from the metadata items. The Apple keys are preferred over the ©mak and
+// ©mod entries.
+func (md quickTimeMetadata) camera() exifdata.Camera {
+	text := func(key string, boxType mp4.BoxType) string {
+		value := md.item(key)
+		if len(value) == 0 {
+			value = md.userData[boxType]
+		}
+		return strings.TrimRight(strings.TrimSpace(string(value)), "\x00")
+	}
+	return exifdata.Camera{
+		Make:  text(appleMakeKey, makeBoxType),
+		Model: text(appleModelKey, modelBoxType),
+	}
+}
+
+// getQuickTimeMetadata walks the moov/meta and moov/udta boxes collecting metadata items
+// Ref: https://developer.apple.com/documentation/quicktime-file-format/metadata_atoms_and_types
+func getQuickTimeMetadata(r io.ReadSeeker) (quickTimeMetadata, error) {
+	md := quickTimeMetadata{
+		keys:     make(map[uint32]string),
+		items:    make(map[uint32][]byte),
+		userData: make(map[mp4.BoxType][]byte),
+	}
+
+	_, err := mp4.ReadBoxStructure(r, func(h *mp4.ReadHandle) (interface{}, error) {
+		path := h.Path
+		switch {
+		case isPath(path, "moov"),
+			isPath(path, "moov", "meta"),
+			isPath(path, "moov", "meta", "ilst"),
+			isPath(path, "moov", "udta"),
+			isPath(path, "moov", "udta", "meta"),
+			isPath(path, "moov", "udta", "meta", "ilst"):
+			return h.Expand()
+		case isPath(path, "moov", "meta", "keys"):
+			box, _, err := h.ReadPayload()
+			if err != nil {
+				return nil, err
+			}
+			keys, ok := box.(*mp4.Keys)
+			if !ok {
+				return nil, nil
+			}
+			for i, entry := range keys.Entries {
+				md.keys[uint32(i+1)] = string(entry.KeyValue)
+			}
+		case len(path) == 4 && isPath(path[:3], "moov", "meta", "ilst"):
+			value, err := readItemValue(h)
+			if err != nil {
+				return nil, nil
+			}
+			md.items[binary.BigEndian.Uint32(path[3][:])] = value
+		case len(path) == 5 && isPath(path[:4], "moov", "udta", "meta", "ilst") && isUserDataBoxType(path[4]):
+			value, err := readItemValue(h)
+			if err != nil {
+				return nil, nil
+			}
+			md.userData[path[4]] = value
+		case len(path) == 3 && isPath(path[:2], "moov", "udta") && isUserDataBoxType(path[2]):
+			value, err := readUserDataText(h)
+			if err != nil {
+				return nil, nil
+			}
+			md.userData[path[2]] = value
+		}
+		return nil, nil
+	})
+	return md, err
+}
+
+// readItemValue returns the value of the data box contained by a metadata item
+// Ref: https://developer.apple.com/documentation/quicktime-file-format/value_atom
+func readItemValue(h *mp4.ReadHandle) ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, 0, h.BoxInfo.Size))
+	if _, err := h.ReadData(buf); err != nil {
 		return nil, err
 	}
-	defer f.Close()
 
-	return mp4.ExtractBoxesWithPayload(f, nil, []mp4.BoxPath{mvhdBoxPath})
+	// size(4) + type(4) + data type(4) + locale(4)
+	data := buf.Bytes()
+	if len(data) < 16 || !bytes.Equal(data[4:8], []byte("data")) {
+		return nil, errors.New("metadata item does not contain a data box")
+	}
+	size := binary.BigEndian.Uint32(data[0:4])
+	if size < 16 || int(size) > len(data) {
+		return nil, errors.New("invalid metadata item data box size")
+	}
+	return data[16:size], nil
 }
 
-// timeSince1904 returns the time from the provided 32-bit int. CreationTime in
-// mvhd4 box is the represented as seconds since 1904.
-func timeSince1904(sec uint32) time.Time {
-	return time.Date(1904, time.January, 1, 0, 0, 0, 0, time.UTC).Add(time.Second * time.Duration(sec))
+// readUserDataText returns the text of a QuickTime international text user data box
+// Ref: https://developer.apple.com/documentation/quicktime-file-format/user_data_atoms
+func readUserDataText(h *mp4.ReadHandle) ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, 0, h.BoxInfo.Size))
+	if _, err := h.ReadData(buf); err != nil {
+		return nil, err
+	}
+
+	// text size(2) + language(2)
+	data := buf.Bytes()
+	if len(data) < 4 {
+		return nil, errors.New("invalid user data text")
+	}
+	size := int(binary.BigEndian.Uint16(data[0:2]))
+	if size > len(data)-4 {
+		return nil, errors.New("invalid user data text size")
+	}
+	return data[4 : 4+size], nil
+}
+
+func parseMetadataDate(value []byte) (time.Time, error) {
+	s := strings.TrimRight(strings.TrimSpace(string(value)), "\x00")
+	if exifdata.IsZeroDate(s) {
+		return time.Time{}, errors.New("metadata date is zeroed")
+	}
+	for _, layout := range metadataDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.New("unknown metadata date format")
+}
+
+// isPath returns true if the BoxPath matches the provided box types
+func isPath(path mp4.BoxPath, types ...string) bool {
+	if len(path) != len(types) {
+		return false
+	}
+	for i := range types {
+		if path[i] != mp4.StrToBoxType(types[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isUserDataBoxType(boxType mp4.BoxType) bool {
+	for _, t := range userDataBoxTypes {
+		if t == boxType {
+			return true
+		}
+	}
+	return false
+}
+
+func firstNonZero(a, b uint64) uint64 {
+	if a != 0 {
+		return a
+	}
+	return b
+}
+
+// timeSince1904 returns the time from the provided int. CreationTime in the
+// mvhd, tkhd and mdhd boxes is represented as seconds since 1904. Version 0
+// boxes use 32-bit values and version 1 boxes use 64-bit values.
+func timeSince1904(sec uint64) (time.Time, error) {
+	if sec >= maxSecondsSince1904 {
+		return time.Time{}, fmt.Errorf("creation time %d is out of range", sec)
+	}
+	return time.Unix(int64(sec)-unixEpochSince1904, 0).UTC(), nil
 }
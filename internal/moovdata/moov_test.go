@@ -0,0 +1,222 @@
+package moovdata
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	mp4 "github.com/abema/go-mp4"
+	"github.com/dtrejod/goexif/internal/exifdata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// secondsSince1904 is the number of seconds between 1904-01-01 and 2020-06-26 23:19:26 UTC
+const secondsSince1904 = 3676058366
+
+func TestGetTimeFromBoxes(t *testing.T) {
+	expected := time.Date(2020, 06, 26, 23, 19, 26, 0, time.UTC)
+
+	t.Run("mvhd version 0", func(t *testing.T) {
+		actual, err := getTimeFromBoxes([]*mp4.BoxInfoWithPayload{
+			{Payload: &mp4.Mvhd{CreationTimeV0: secondsSince1904}},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("mvhd version 1", func(t *testing.T) {
+		mvhd := &mp4.Mvhd{CreationTimeV1: secondsSince1904}
+		mvhd.SetVersion(1)
+		actual, err := getTimeFromBoxes([]*mp4.BoxInfoWithPayload{{Payload: mvhd}})
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("zero mvhd falls back to tkhd", func(t *testing.T) {
+		actual, err := getTimeFromBoxes([]*mp4.BoxInfoWithPayload{
+			{Payload: &mp4.Mvhd{}},
+			{Payload: &mp4.Mdhd{CreationTimeV0: 1}},
+			{Payload: &mp4.Tkhd{}},
+			{Payload: &mp4.Tkhd{CreationTimeV0: secondsSince1904}},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("missing mvhd falls back to mdhd", func(t *testing.T) {
+		actual, err := getTimeFromBoxes([]*mp4.BoxInfoWithPayload{
+			{Payload: &mp4.Mdhd{CreationTimeV0: secondsSince1904}},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("out of range", func(t *testing.T) {
+		mvhd := &mp4.Mvhd{CreationTimeV1: math.MaxUint64}
+		mvhd.SetVersion(1)
+		_, err := getTimeFromBoxes([]*mp4.BoxInfoWithPayload{{Payload: mvhd}})
+		assert.Error(t, err)
+	})
+
+	t.Run("all zero is no metadata", func(t *testing.T) {
+		_, err := getTimeFromBoxes([]*mp4.BoxInfoWithPayload{
+			{Payload: &mp4.Mvhd{}},
+			{Payload: &mp4.Tkhd{}},
+			{Payload: &mp4.Mdhd{}},
+		})
+		assert.Error(t, err)
+	})
+}
+
+// box returns an encoded mp4 box with the provided type and payload
+func box(boxType string, payload ...[]byte) []byte {
+	data := bytes.Join(payload, nil)
+	out := binary.BigEndian.AppendUint32(nil, uint32(8+len(data)))
+	return append(append(out, boxType...), data...)
+}
+
+// writeMoov writes a minimal MooV file with the provided moov children and returns its path
+func writeMoov(t *testing.T, children ...[]byte) string {
+	t.Helper()
+	ftyp := box("ftyp", []byte("qt  "), make([]byte, 4), []byte("qt  "))
+	mvhd := box("mvhd", make([]byte, 4), binary.BigEndian.AppendUint32(nil, secondsSince1904), make([]byte, 92))
+	data := append(ftyp, box("moov", append([][]byte{mvhd}, children...)...)...)
+
+	path := filepath.Join(t.TempDir(), "video.mov")
+	require.NoError(t, os.WriteFile(path, data, 0644))
+	return path
+}
+
+func TestGetTime(t *testing.T) {
+	t.Run("with apple creation date", func(t *testing.T) {
+		key := []byte(appleCreationDateKey)
+		keys := box("keys",
+			make([]byte, 4),
+			binary.BigEndian.AppendUint32(nil, 1),
+			binary.BigEndian.AppendUint32(nil, uint32(8+len(key))), []byte("mdta"), key,
+		)
+		item := box("\x00\x00\x00\x01", box("data", []byte{0, 0, 0, 1}, make([]byte, 4), []byte("2020-06-26T16:19:26-0700")))
+		path := writeMoov(t,
+			box("meta", box("hdlr", make([]byte, 8), []byte("mdta"), make([]byte, 13)), keys, box("ilst", item)),
+			box("udta", box("\xa9day", []byte{0, 24, 0, 0}, []byte("2001-01-01T00:00:00+0000"))),
+		)
+
+		actual, err := GetTime(path)
+		require.NoError(t, err)
+		assert.True(t, time.Date(2020, 06, 26, 23, 19, 26, 0, time.UTC).Equal(actual))
+		_, offset := actual.Zone()
+		assert.Equal(t, -7*60*60, offset)
+	})
+
+	t.Run("with user data day", func(t *testing.T) {
+		day := []byte("2020-06-27T08:19:26+0900")
+		path := writeMoov(t,
+			box("udta", box("\xa9day", binary.BigEndian.AppendUint16(nil, uint16(len(day))), []byte{0x15, 0xc7}, day)),
+		)
+
+		actual, err := GetTime(path)
+		require.NoError(t, err)
+		assert.True(t, time.Date(2020, 06, 26, 23, 19, 26, 0, time.UTC).Equal(actual))
+	})
+
+	t.Run("with item list day", func(t *testing.T) {
+		day := box("\xa9day", box("data", []byte{0, 0, 0, 1}, make([]byte, 4), []byte("20200626T231926.000Z")))
+		path := writeMoov(t,
+			box("udta", box("meta", make([]byte, 4), box("ilst", day))),
+		)
+
+		actual, err := GetTime(path)
+		require.NoError(t, err)
+		assert.True(t, time.Date(2020, 06, 26, 23, 19, 26, 0, time.UTC).Equal(actual))
+	})
+
+	t.Run("with zeroed user data day", func(t *testing.T) {
+		day := []byte("0000-00-00T00:00:00+0000")
+		path := writeMoov(t,
+			box("udta", box("\xa9day", binary.BigEndian.AppendUint16(nil, uint16(len(day))), []byte{0x15, 0xc7}, day)),
+		)
+
+		// the mvhd creation time is used instead
+		actual, err := GetTime(path)
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2020, 06, 26, 23, 19, 26, 0, time.UTC), actual)
+	})
+
+	t.Run("without metadata items", func(t *testing.T) {
+		path := writeMoov(t)
+
+		actual, err := GetTime(path)
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2020, 06, 26, 23, 19, 26, 0, time.UTC), actual)
+	})
+}
+
+func TestGetCamera(t *testing.T) {
+	t.Run("with apple keys and user data", func(t *testing.T) {
+		key := []byte(appleMakeKey)
+		keys := box("keys",
+			make([]byte, 4),
+			binary.BigEndian.AppendUint32(nil, 1),
+			binary.BigEndian.AppendUint32(nil, uint32(8+len(key))), []byte("mdta"), key,
+		)
+		item := box("\x00\x00\x00\x01", box("data", []byte{0, 0, 0, 1}, make([]byte, 4), []byte("Apple")))
+		model := []byte("iPhone 12")
+		path := writeMoov(t,
+			box("meta", box("hdlr", make([]byte, 8), []byte("mdta"), make([]byte, 13)), keys, box("ilst", item)),
+			box("udta", box("\xa9mod", binary.BigEndian.AppendUint16(nil, uint16(len(model))), []byte{0x15, 0xc7}, model)),
+		)
+
+		actual, err := GetCamera(path)
+		require.NoError(t, err)
+		assert.Equal(t, exifdata.Camera{Make: "Apple", Model: "iPhone 12"}, actual)
+	})
+
+	t.Run("with zeroed user data day", func(t *testing.T) {
+		userData := func(boxType string, value []byte) []byte {
+			return box(boxType, binary.BigEndian.AppendUint16(nil, uint16(len(value))), []byte{0x15, 0xc7}, value)
+		}
+		path := writeMoov(t,
+			box("udta",
+				userData("\xa9day", []byte("0000-00-00T00:00:00+0000")),
+				userData("\xa9mak", []byte("GoPro")),
+				userData("\xa9mod", []byte("HERO9 Black")),
+			),
+		)
+
+		// the zeroed date does not affect the camera
+		actual, err := GetCamera(path)
+		require.NoError(t, err)
+		assert.Equal(t, exifdata.Camera{Make: "GoPro", Model: "HERO9 Black"}, actual)
+	})
+
+	t.Run("without metadata items", func(t *testing.T) {
+		_, err := GetCamera(writeMoov(t))
+		assert.Error(t, err)
+	})
+}
+
+func TestGetContentIdentifier(t *testing.T) {
+	id := "8E0C8AA8-5D4E-4B0F-9E6C-3F1B2E1F6E9A"
+	key := []byte(appleContentIdentifierKey)
+	keys := box("keys",
+		make([]byte, 4),
+		binary.BigEndian.AppendUint32(nil, 1),
+		binary.BigEndian.AppendUint32(nil, uint32(8+len(key))), []byte("mdta"), key,
+	)
+	item := box("\x00\x00\x00\x01", box("data", []byte{0, 0, 0, 1}, make([]byte, 4), []byte(id)))
+	path := writeMoov(t,
+		box("meta", box("hdlr", make([]byte, 8), []byte("mdta"), make([]byte, 13)), keys, box("ilst", item)),
+	)
+
+	actual, err := GetContentIdentifier(path)
+	require.NoError(t, err)
+	assert.Equal(t, id, actual)
+
+	_, err = GetContentIdentifier(writeMoov(t))
+	assert.Error(t, err)
+}
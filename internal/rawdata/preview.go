@@ -0,0 +1,114 @@
+package rawdata
+
+import (
+	"bytes"
+	"errors"
+	"image/jpeg"
+	"io"
+	"os"
+	"sort"
+)
+
+const (
+	// jpegCompression and oldJpegCompression are the TIFF Compression values of JPEG compressed strips
+	jpegCompression    = 7
+	oldJpegCompression = 6
+	// maxPreviewLength bounds the size of a preview so corrupt lengths do not exhaust memory
+	maxPreviewLength = 64 << 20
+)
+
+// preview is the location of a JPEG embedded in a RAW file
+type preview struct {
+	offset uint32
+	length uint32
+}
+
+// GetPreview returns the largest JPEG preview embedded in the RAW media referenced in the provided path. RAW sensor
+// data that is stored as lossless JPEG is not a preview, so only JPEGs that can be decoded are returned.
+func GetPreview(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	header := make([]byte, rafJpegOffset+8)
+	n, err := io.ReadFull(f, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+
+	var previews []preview
+	if Format(header[:n]) == RAF {
+		offset, length, err := rafJpeg(header[:n])
+		if err != nil {
+			return nil, err
+		}
+		previews = append(previews, preview{offset: offset, length: length})
+	} else {
+		t, err := newTiffReader(f)
+		if err != nil {
+			return nil, err
+		}
+		previews = tiffPreviews(t)
+	}
+
+	// prefer the largest preview since it is the closest to the RAW image
+	sort.SliceStable(previews, func(i, j int) bool {
+		return previews[i].length > previews[j].length
+	})
+	for _, p := range previews {
+		if p.length > maxPreviewLength {
+			continue
+		}
+		data := make([]byte, p.length)
+		if _, err := f.ReadAt(data, int64(p.offset)); err != nil {
+			continue
+		}
+		if _, err := jpeg.DecodeConfig(bytes.NewReader(data)); err != nil {
+			continue
+		}
+		return data, nil
+	}
+	return nil, errors.New("could not find jpeg preview")
+}
+
+// tiffPreviews returns the locations of the JPEGs referenced by the IFDs of a TIFF-based RAW file. Previews are found as
+// JPEG interchange format thumbnails, single JPEG compressed strips and the Panasonic JpgFromRaw tag.
+func tiffPreviews(t *tiffReader) []preview {
+	var previews []preview
+	t.walk(func(entries []ifdEntry) {
+		var (
+			jpegOffset, jpegLength     uint32
+			stripOffsets, stripLengths []uint32
+			compression                uint32
+		)
+		for _, e := range entries {
+			switch e.tag {
+			case jpegInterchangeFormatTag:
+				jpegOffset, _ = t.uint(e)
+			case jpegInterchangeFormatLengthTag:
+				jpegLength, _ = t.uint(e)
+			case stripOffsetsTag:
+				stripOffsets, _ = t.uints(e)
+			case stripByteCountsTag:
+				stripLengths, _ = t.uints(e)
+			case compressionTag:
+				compression, _ = t.uint(e)
+			case panasonicJpgFromRawTag:
+				if offset, length, err := t.blob(e); err == nil {
+					previews = append(previews, preview{offset: offset, length: length})
+				}
+			}
+		}
+
+		if jpegOffset > 0 && jpegLength > 0 {
+			previews = append(previews, preview{offset: jpegOffset, length: jpegLength})
+		}
+		if (compression == jpegCompression || compression == oldJpegCompression) &&
+			len(stripOffsets) == 1 && len(stripLengths) == 1 && stripLengths[0] > 0 {
+			previews = append(previews, preview{offset: stripOffsets[0], length: stripLengths[0]})
+		}
+	})
+	return previews
+}
@@ -0,0 +1,148 @@
+package rawdata
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+)
+
+const (
+	// rafMagic starts every Fujifilm RAF file
+	rafMagic = "FUJIFILMCCD-RAW "
+	// rafJpegOffset is the offset of the big-endian offset and length of the embedded JPEG in the RAF header
+	// Ref: https://exiftool.org/TagNames/FujiFilm.html#RAF
+	rafJpegOffset = 84
+)
+
+// Extensions of the RAW formats identified by Format
+const (
+	DNG = "dng"
+	CR2 = "cr2"
+	NEF = "nef"
+	ARW = "arw"
+	ORF = "orf"
+	RW2 = "rw2"
+	PEF = "pef"
+	RAF = "raf"
+	CR3 = "cr3"
+)
+
+// tiffMagics are the TIFF-like headers of RAW formats that replace the TIFF magic number. The TIFF structure
+// otherwise follows the standard.
+var tiffMagics = map[string]string{
+	"IIRO":    ORF,
+	"IIRS":    ORF,
+	"MMOR":    ORF,
+	"IIU\x00": RW2,
+	"II*\x00": "",
+	"MM\x00*": "",
+}
+
+// Format returns the extension of the RAW format identified from the header of a file, or an empty string if the header
+// is not a known RAW format. CR3 is identified by its ftyp brand. TIFF-based formats are told apart by the DNGVersion
+// and Make tags of IFD0, so the header should hold IFD0; a few kilobytes are enough for all known cameras.
+func Format(header []byte) string {
+	if bytes.HasPrefix(header, []byte(rafMagic)) {
+		return RAF
+	}
+	// CR3 is an ISOBMFF file with its own major brand
+	if len(header) >= 12 && string(header[4:12]) == "ftypcrx " {
+		return CR3
+	}
+	if len(header) < 10 {
+		return ""
+	}
+	format, ok := tiffMagics[string(header[:4])]
+	if !ok || format != "" {
+		return format
+	}
+	// CR2 marks itself right after the TIFF header
+	if string(header[8:10]) == "CR" {
+		return CR2
+	}
+
+	t, err := newTiffReader(bytes.NewReader(header))
+	if err != nil {
+		return ""
+	}
+	entries, _, err := t.readIfd(t.first)
+	if err != nil {
+		return ""
+	}
+	var cameraMake string
+	for _, e := range entries {
+		switch e.tag {
+		case dngVersionTag:
+			return DNG
+		case makeTag:
+			cameraMake, _ = t.ascii(e)
+		}
+	}
+
+	cameraMake = strings.ToUpper(cameraMake)
+	switch {
+	case strings.HasPrefix(cameraMake, "NIKON"):
+		return NEF
+	case strings.HasPrefix(cameraMake, "SONY"):
+		return ARW
+	case strings.HasPrefix(cameraMake, "PENTAX"), strings.HasPrefix(cameraMake, "RICOH"):
+		return PEF
+	default:
+		return ""
+	}
+}
+
+// ExifReader returns a reader of the TIFF structure holding the EXIF metadata of the RAW media read from r. RAF embeds
+// the EXIF in its JPEG preview, and ORF and RW2 replace the TIFF magic number, so their EXIF is not found by a plain
+// search. Any other media is returned from its start.
+func ExifReader(r io.ReadSeeker) (io.Reader, error) {
+	header := make([]byte, rafJpegOffset+8)
+	n, err := io.ReadFull(r, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+	header = header[:n]
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	if bytes.HasPrefix(header, []byte(rafMagic)) {
+		offset, length, err := rafJpeg(header)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := r.Seek(int64(offset), io.SeekStart); err != nil {
+			return nil, err
+		}
+		return io.LimitReader(r, int64(length)), nil
+	}
+
+	if len(header) >= 4 {
+		if format := tiffMagics[string(header[:4])]; format == ORF || format == RW2 {
+			patched := []byte{header[0], header[1], 0x2a, 0x00}
+			if header[0] == 'M' {
+				patched[2], patched[3] = 0x00, 0x2a
+			}
+			if _, err := r.Seek(4, io.SeekStart); err != nil {
+				return nil, err
+			}
+			return io.MultiReader(bytes.NewReader(patched), r), nil
+		}
+	}
+	return r, nil
+}
+
+// rafJpeg returns the offset and length of the JPEG preview from the RAF header
+func rafJpeg(header []byte) (uint32, uint32, error) {
+	if len(header) < rafJpegOffset+8 {
+		return 0, 0, errors.New("raf header too short")
+	}
+	offset := binary.BigEndian.Uint32(header[rafJpegOffset:])
+	length := binary.BigEndian.Uint32(header[rafJpegOffset+4:])
+	if offset == 0 || length == 0 {
+		return 0, 0, errors.New("raf jpeg preview not found")
+	}
+	return offset, length, nil
+}
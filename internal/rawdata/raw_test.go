@@ -0,0 +1,175 @@
+package rawdata
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/jpeg"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testEntry is an IFD0 entry written into a test TIFF
+type testEntry struct {
+	tag   uint16
+	typ   uint16
+	count uint32
+	data  []byte
+}
+
+func longEntry(tag uint16, v uint32) testEntry {
+	return testEntry{tag: tag, typ: longType, count: 1, data: binary.LittleEndian.AppendUint32(nil, v)}
+}
+
+func asciiEntry(tag uint16, v string) testEntry {
+	return testEntry{tag: tag, typ: asciiType, count: uint32(len(v) + 1), data: append([]byte(v), 0)}
+}
+
+// buildTiff returns a little-endian TIFF with the provided magic. The payload is written at offset 8 followed by IFD0
+// with the provided entries. Entry data longer than 4 bytes is written after IFD0.
+func buildTiff(magic string, payload []byte, entries ...testEntry) []byte {
+	ifdOffset := 8 + len(payload)
+	dataOffset := ifdOffset + 2 + len(entries)*12 + 4
+
+	out := append([]byte(magic), binary.LittleEndian.AppendUint32(nil, uint32(ifdOffset))...)
+	out = append(out, payload...)
+	out = binary.LittleEndian.AppendUint16(out, uint16(len(entries)))
+	var data []byte
+	for _, e := range entries {
+		out = binary.LittleEndian.AppendUint16(out, e.tag)
+		out = binary.LittleEndian.AppendUint16(out, e.typ)
+		out = binary.LittleEndian.AppendUint32(out, e.count)
+		if len(e.data) > 4 {
+			out = binary.LittleEndian.AppendUint32(out, uint32(dataOffset+len(data)))
+			data = append(data, e.data...)
+		} else {
+			out = append(out, append(e.data, make([]byte, 4-len(e.data))...)...)
+		}
+	}
+	out = binary.LittleEndian.AppendUint32(out, 0)
+	return append(out, data...)
+}
+
+// buildRaf returns a RAF file embedding the provided JPEG
+func buildRaf(jpg []byte) []byte {
+	header := make([]byte, rafJpegOffset+8+8)
+	copy(header, rafMagic)
+	binary.BigEndian.PutUint32(header[rafJpegOffset:], uint32(len(header)))
+	binary.BigEndian.PutUint32(header[rafJpegOffset+4:], uint32(len(jpg)))
+	return append(header, jpg...)
+}
+
+func encodeJpeg(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, image.NewGray(image.Rect(0, 0, 8, 8)), nil))
+	return buf.Bytes()
+}
+
+func writeFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, data, 0644))
+	return path
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   []byte
+		expected string
+	}{
+		{"raf", buildRaf(nil), RAF},
+		{"orf", buildTiff("IIRO", nil, asciiEntry(makeTag, "OLYMPUS")), ORF},
+		{"rw2", buildTiff("IIU\x00", nil, asciiEntry(makeTag, "Panasonic")), RW2},
+		{"cr2", buildTiff("II*\x00", []byte("CR\x02\x00\x00\x00\x00\x00")), CR2},
+		{"dng", buildTiff("II*\x00", nil, asciiEntry(makeTag, "NIKON CORPORATION"), longEntry(dngVersionTag, 0x00000401)), DNG},
+		{"nef", buildTiff("II*\x00", nil, asciiEntry(makeTag, "NIKON CORPORATION")), NEF},
+		{"arw", buildTiff("II*\x00", nil, asciiEntry(makeTag, "SONY")), ARW},
+		{"pef", buildTiff("II*\x00", nil, asciiEntry(makeTag, "PENTAX Corporation")), PEF},
+		{"tiff", buildTiff("II*\x00", nil, asciiEntry(makeTag, "Canon")), ""},
+		{"jpeg", []byte("\xff\xd8\xff\xe0\x00\x10JFIF\x00"), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Format(tt.header))
+		})
+	}
+}
+
+func TestExifReader(t *testing.T) {
+	t.Run("orf magic is replaced", func(t *testing.T) {
+		orf := buildTiff("IIRO", nil, asciiEntry(makeTag, "OLYMPUS"))
+		r, err := ExifReader(bytes.NewReader(orf))
+		require.NoError(t, err)
+		actual, err := io.ReadAll(r)
+		require.NoError(t, err)
+		assert.Equal(t, append([]byte("II*\x00"), orf[4:]...), actual)
+	})
+
+	t.Run("raf returns embedded jpeg", func(t *testing.T) {
+		jpg := encodeJpeg(t)
+		r, err := ExifReader(bytes.NewReader(buildRaf(jpg)))
+		require.NoError(t, err)
+		actual, err := io.ReadAll(r)
+		require.NoError(t, err)
+		assert.Equal(t, jpg, actual)
+	})
+
+	t.Run("other media is unchanged", func(t *testing.T) {
+		tiff := buildTiff("II*\x00", nil, asciiEntry(makeTag, "Canon"))
+		r, err := ExifReader(bytes.NewReader(tiff))
+		require.NoError(t, err)
+		actual, err := io.ReadAll(r)
+		require.NoError(t, err)
+		assert.Equal(t, tiff, actual)
+	})
+}
+
+func TestGetPreview(t *testing.T) {
+	jpg := encodeJpeg(t)
+
+	t.Run("jpeg interchange format", func(t *testing.T) {
+		// the larger strip is lossless sensor data that cannot be decoded
+		sensor := append([]byte("\xff\xd8\xff\xc3"), make([]byte, len(jpg))...)
+		payload := append(append([]byte{}, jpg...), sensor...)
+		path := writeFile(t, "image.nef", buildTiff("II*\x00", payload,
+			asciiEntry(makeTag, "NIKON CORPORATION"),
+			longEntry(compressionTag, jpegCompression),
+			longEntry(stripOffsetsTag, uint32(8+len(jpg))),
+			longEntry(stripByteCountsTag, uint32(len(sensor))),
+			longEntry(jpegInterchangeFormatTag, 8),
+			longEntry(jpegInterchangeFormatLengthTag, uint32(len(jpg))),
+		))
+
+		actual, err := GetPreview(path)
+		require.NoError(t, err)
+		assert.Equal(t, jpg, actual)
+	})
+
+	t.Run("panasonic jpg from raw", func(t *testing.T) {
+		path := writeFile(t, "image.rw2", buildTiff("IIU\x00", nil,
+			testEntry{tag: panasonicJpgFromRawTag, typ: undefinedType, count: uint32(len(jpg)), data: jpg},
+		))
+
+		actual, err := GetPreview(path)
+		require.NoError(t, err)
+		assert.Equal(t, jpg, actual)
+	})
+
+	t.Run("raf", func(t *testing.T) {
+		actual, err := GetPreview(writeFile(t, "image.raf", buildRaf(jpg)))
+		require.NoError(t, err)
+		assert.Equal(t, jpg, actual)
+	})
+
+	t.Run("without preview", func(t *testing.T) {
+		_, err := GetPreview(writeFile(t, "image.arw", buildTiff("II*\x00", nil, asciiEntry(makeTag, "SONY"))))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,206 @@
+package rawdata
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+const (
+	// maxIfdEntries bounds the entries read from a single IFD so corrupt files are not read endlessly
+	maxIfdEntries = 1024
+	// maxIfds bounds the IFDs walked in a single file
+	maxIfds = 64
+)
+
+// TIFF tags used to locate the embedded previews
+// Ref: https://exiftool.org/TagNames/EXIF.html
+const (
+	makeTag                        = 0x010f
+	compressionTag                 = 0x0103
+	stripOffsetsTag                = 0x0111
+	stripByteCountsTag             = 0x0117
+	subIfdsTag                     = 0x014a
+	jpegInterchangeFormatTag       = 0x0201
+	jpegInterchangeFormatLengthTag = 0x0202
+	dngVersionTag                  = 0xc612
+	// panasonicJpgFromRawTag is the full size preview of RW2 files
+	panasonicJpgFromRawTag = 0x002e
+)
+
+// TIFF field types
+const (
+	byteType      = 1
+	asciiType     = 2
+	shortType     = 3
+	longType      = 4
+	undefinedType = 7
+	ifdType       = 13
+)
+
+// ifdEntry is a single tag of an IFD. The value holds the tag's data when it fits in 4 bytes, otherwise it holds the
+// offset of the data.
+type ifdEntry struct {
+	tag   uint16
+	typ   uint16
+	count uint32
+	value [4]byte
+}
+
+// tiffReader reads IFDs of a TIFF structure
+// Ref: https://www.itu.int/itudoc/itu-t/com16/tiff-fx/docs/tiff6.pdf
+type tiffReader struct {
+	r     io.ReaderAt
+	order binary.ByteOrder
+	// first is the offset of the first IFD
+	first uint32
+}
+
+// newTiffReader returns a reader of the TIFF structure found at the start of r. The magic number that follows the byte
+// order is not checked, because RAW formats such as ORF and RW2 replace it with their own.
+func newTiffReader(r io.ReaderAt) (*tiffReader, error) {
+	header := make([]byte, 8)
+	if _, err := r.ReadAt(header, 0); err != nil {
+		return nil, err
+	}
+
+	var order binary.ByteOrder
+	switch string(header[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return nil, errors.New("invalid tiff byte order")
+	}
+	return &tiffReader{r: r, order: order, first: order.Uint32(header[4:])}, nil
+}
+
+// readIfd returns the entries of the IFD at the provided offset and the offset of the next IFD
+func (t *tiffReader) readIfd(offset uint32) ([]ifdEntry, uint32, error) {
+	buf := make([]byte, 2)
+	if _, err := t.r.ReadAt(buf, int64(offset)); err != nil {
+		return nil, 0, err
+	}
+	n := int(t.order.Uint16(buf))
+	if n == 0 || n > maxIfdEntries {
+		return nil, 0, errors.New("invalid ifd entry count")
+	}
+
+	buf = make([]byte, n*12+4)
+	if _, err := t.r.ReadAt(buf, int64(offset)+2); err != nil {
+		return nil, 0, err
+	}
+	entries := make([]ifdEntry, n)
+	for i := range entries {
+		b := buf[i*12:]
+		entries[i] = ifdEntry{
+			tag:   t.order.Uint16(b),
+			typ:   t.order.Uint16(b[2:]),
+			count: t.order.Uint32(b[4:]),
+		}
+		copy(entries[i].value[:], b[8:12])
+	}
+	return entries, t.order.Uint32(buf[n*12:]), nil
+}
+
+// uints returns the SHORT, LONG or IFD values of the entry
+func (t *tiffReader) uints(e ifdEntry) ([]uint32, error) {
+	var size int
+	switch e.typ {
+	case shortType:
+		size = 2
+	case longType, ifdType:
+		size = 4
+	default:
+		return nil, errors.New("unexpected tiff field type")
+	}
+	if e.count == 0 || e.count > maxIfdEntries {
+		return nil, errors.New("unexpected tiff field count")
+	}
+
+	data := e.value[:]
+	if n := int(e.count) * size; n > 4 {
+		data = make([]byte, n)
+		if _, err := t.r.ReadAt(data, int64(t.order.Uint32(e.value[:]))); err != nil {
+			return nil, err
+		}
+	}
+	out := make([]uint32, e.count)
+	for i := range out {
+		if size == 2 {
+			out[i] = uint32(t.order.Uint16(data[i*2:]))
+		} else {
+			out[i] = t.order.Uint32(data[i*4:])
+		}
+	}
+	return out, nil
+}
+
+// uint returns the first SHORT, LONG or IFD value of the entry
+func (t *tiffReader) uint(e ifdEntry) (uint32, error) {
+	values, err := t.uints(e)
+	if err != nil {
+		return 0, err
+	}
+	return values[0], nil
+}
+
+// ascii returns the ASCII value of the entry without the trailing NUL
+func (t *tiffReader) ascii(e ifdEntry) (string, error) {
+	if e.typ != asciiType || e.count == 0 || e.count > maxIfdEntries {
+		return "", errors.New("unexpected tiff ascii field")
+	}
+	data := e.value[:]
+	if e.count > 4 {
+		data = make([]byte, e.count)
+		if _, err := t.r.ReadAt(data, int64(t.order.Uint32(e.value[:]))); err != nil {
+			return "", err
+		}
+	}
+	data = data[:e.count]
+	for i, c := range data {
+		if c == 0 {
+			return string(data[:i]), nil
+		}
+	}
+	return string(data), nil
+}
+
+// blob returns the offset and length of the data of a BYTE or UNDEFINED entry
+func (t *tiffReader) blob(e ifdEntry) (uint32, uint32, error) {
+	if (e.typ != byteType && e.typ != undefinedType) || e.count <= 4 {
+		return 0, 0, errors.New("unexpected tiff blob field")
+	}
+	return t.order.Uint32(e.value[:]), e.count, nil
+}
+
+// walk calls fn for every IFD reachable from the first IFD through the IFD chain and SubIFDs
+func (t *tiffReader) walk(fn func(entries []ifdEntry)) {
+	visited := make(map[uint32]struct{})
+	queue := []uint32{t.first}
+	for len(queue) > 0 && len(visited) < maxIfds {
+		offset := queue[0]
+		queue = queue[1:]
+		if _, ok := visited[offset]; ok || offset == 0 {
+			continue
+		}
+		visited[offset] = struct{}{}
+
+		entries, next, err := t.readIfd(offset)
+		if err != nil {
+			continue
+		}
+		fn(entries)
+
+		queue = append(queue, next)
+		for _, e := range entries {
+			if e.tag != subIfdsTag {
+				continue
+			}
+			if subIfds, err := t.uints(e); err == nil {
+				queue = append(queue, subIfds...)
+			}
+		}
+	}
+}
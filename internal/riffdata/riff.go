@@ -2,26 +2,77 @@ package riffdata
 
 import (
 	"bytes"
+	"encoding/binary"
 	"io"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/dtrejod/goexif/internal/exifdata"
 	"github.com/go-errors/errors"
 	"golang.org/x/image/riff"
 )
 
 const (
-	// riffIDITDateLayout is the string time layout used by the IDIT chunk
-	riffIDITDateLayout = time.ANSIC
+	// maxChunkLen is the upper bound on the size of a metadata chunk that is read into memory
+	maxChunkLen = 1 << 20
+	// bextDateOffset is the offset of the OriginationDate in the bext chunk. It follows the Description, Originator and
+	// OriginatorReference fields, and is followed by the OriginationTime.
+	bextDateOffset = 256 + 32 + 32
+	bextDateLen    = 10
+	bextTimeLen    = 8
 )
 
 var (
 	// idit is the riff tag associated with the DateTimeOriginal riff tag
 	// Ref: https://exiftool.org/TagNames/RIFF.html
 	idit = riff.FourCC{'I', 'D', 'I', 'T'}
+	// icrd is the riff INFO tag associated with the DateCreated riff tag
+	// Ref: https://exiftool.org/TagNames/RIFF.html#Info
+	icrd = riff.FourCC{'I', 'C', 'R', 'D'}
+	// strd is the riff tag associated with additional stream data. Nikon and
+	// Fuji cameras store EXIF metadata in it.
+	// Ref: https://exiftool.org/TagNames/RIFF.html#AVIF
+	strd = riff.FourCC{'s', 't', 'r', 'd'}
+	// movi is the riff list type that contains the audio and video data
+	movi = riff.FourCC{'m', 'o', 'v', 'i'}
+	// bext is the Broadcast Wave Format chunk holding the origination date and time of WAV recordings
+	// Ref: https://tech.ebu.ch/docs/tech/tech3285.pdf
+	bext = riff.FourCC{'b', 'e', 'x', 't'}
+
+	// avifHeader prefixes the stream data containing a little-endian EXIF IFD
+	avifHeader = []byte("AVIF")
+
+	// riffDateLayouts are the known layouts of dates found in RIFF chunks
+	riffDateLayouts = []string{
+		time.ANSIC,
+		"2006:01:02 15:04:05",
+		"2006:01:02 15:04",
+		"2006:01:02",
+		"2006-01-02T15:04:05",
+		"2006-01-02T15:04:05Z07:00",
+		"2006-01-02 15:04:05",
+		"2006-01-02 15:04",
+		"2006-01-02",
+		"2006/01/02 15:04:05",
+		"2006/01/02",
+		"Jan 2 2006",
+		"Jan 2, 2006",
+		"2 Jan 2006",
+	}
 )
 
-// GetTime returns the RIFF metadata Datetime from media referenced in the provided path
+// riffDates are the raw date values collected from known RIFF chunks
+type riffDates struct {
+	idit []byte
+	bext []byte
+	strd []byte
+	icrd []byte
+}
+
+// GetTime returns the RIFF metadata Datetime from media referenced in the provided path. The IDIT chunk of AVI videos
+// and the bext chunk of Broadcast Wave recordings are preferred, followed by EXIF metadata in the strd chunk, and then
+// the INFO ICRD chunk.
 func GetTime(path string) (time.Time, error) {
 	f, err := os.Open(path)
 	if err != nil {
@@ -33,61 +84,261 @@ func GetTime(path string) (time.Time, error) {
 	if err != nil {
 		return time.Time{}, errors.WrapPrefix(err, "could not open file with RIFF reader", 0)
 	}
-	t, found, err := dumpTimeFromReader(r)
-	if err != nil {
+
+	var dates riffDates
+	if _, err := dumpDatesFromReader(r, &dates); err != nil {
 		return time.Time{}, errors.WrapPrefix(err, "unexpected error parsing datetime from RIFF metadata", 0)
 	}
-	if !found {
+	return dates.getTime()
+}
+
+func (d riffDates) getTime() (time.Time, error) {
+	if d.idit == nil && d.bext == nil && d.strd == nil && d.icrd == nil {
 		return time.Time{}, errors.New("could not find datetime in RIFF metadata")
 	}
-	return t, nil
+
+	var lastErr error
+	if d.idit != nil {
+		t, err := parseDate(d.idit)
+		if err == nil {
+			return t, nil
+		}
+		lastErr = errors.WrapPrefix(err, "failed to parse RIFF IDIT chunk", 0)
+	}
+	if d.bext != nil {
+		t, err := getTimeFromBext(d.bext)
+		if err == nil {
+			return t, nil
+		}
+		lastErr = errors.WrapPrefix(err, "failed to parse RIFF bext chunk", 0)
+	}
+	if d.strd != nil {
+		t, err := getTimeFromStreamData(d.strd)
+		if err == nil {
+			return t, nil
+		}
+		lastErr = errors.WrapPrefix(err, "failed to parse RIFF strd chunk", 0)
+	}
+	if d.icrd != nil {
+		t, err := parseDate(d.icrd)
+		if err == nil {
+			return t, nil
+		}
+		lastErr = errors.WrapPrefix(err, "failed to parse RIFF INFO ICRD chunk", 0)
+	}
+	return time.Time{}, lastErr
 }
 
-func dumpTimeFromReader(r *riff.Reader) (time.Time, bool, error) {
+// dumpDatesFromReader collects the dates of the chunks in the provided reader and its lists. True is returned once a
+// valid preferred date was found, so the remaining chunks of all enclosing lists are not read.
+func dumpDatesFromReader(r *riff.Reader, dates *riffDates) (bool, error) {
 	for {
 		chunkID, chunkLen, chunkData, err := r.Next()
 		if err == io.EOF {
-			return time.Time{}, false, nil
+			return false, nil
 		}
 		if err != nil {
-			return time.Time{}, false, err
+			return false, err
 		}
 
 		switch chunkID {
 		case riff.LIST:
-			_, listChunk, err := riff.NewListReader(chunkLen, chunkData)
-			if err != nil {
-				return time.Time{}, false, err
-			}
-			t, found, err := dumpTimeFromReader(listChunk)
+			listType, listChunk, err := riff.NewListReader(chunkLen, chunkData)
 			if err != nil {
-				return time.Time{}, false, err
+				return false, err
 			}
-			if !found {
+			// the movie data never contains metadata and is most of the file
+			if listType == movi {
 				continue
 			}
-			return t, true, nil
+			if done, err := dumpDatesFromReader(listChunk, dates); done || err != nil {
+				return done, err
+			}
 		case idit:
-			t, err := getTimeFromReader(chunkData, chunkLen)
-			return t, true, err
+			if dates.idit, err = readChunk(chunkData, chunkLen); err != nil {
+				return false, err
+			}
+			// the IDIT chunk is the preferred date so stop looking once it holds one
+			if _, err := parseDate(dates.idit); err == nil {
+				return true, nil
+			}
+		case bext:
+			if dates.bext, err = readChunk(chunkData, chunkLen); err != nil {
+				return false, err
+			}
+			// the bext chunk precedes the audio data of Broadcast Wave recordings so stop looking once it holds a date
+			if _, err := getTimeFromBext(dates.bext); err == nil {
+				return true, nil
+			}
+		case strd:
+			if dates.strd != nil {
+				continue
+			}
+			if dates.strd, err = readChunk(chunkData, chunkLen); err != nil {
+				return false, err
+			}
+		case icrd:
+			if dates.icrd, err = readChunk(chunkData, chunkLen); err != nil {
+				return false, err
+			}
 		}
 	}
 }
 
-func getTimeFromReader(r io.Reader, chunkLen uint32) (time.Time, error) {
+func readChunk(r io.Reader, chunkLen uint32) ([]byte, error) {
+	if chunkLen > maxChunkLen {
+		return nil, errors.Errorf("RIFF chunk too large: %d bytes", chunkLen)
+	}
 	buf := bytes.NewBuffer(make([]byte, 0, chunkLen))
 	_, err := io.Copy(buf, r)
 	if err != nil {
-		return time.Time{}, errors.WrapPrefix(err, "failed to copy RIFF IDIT tag into buffer", 0)
+		return nil, errors.WrapPrefix(err, "failed to copy RIFF chunk into buffer", 0)
 	}
+	return buf.Bytes(), nil
+}
 
-	// IDIT chunk is both line feed and null char terminated
-	val := string(bytes.TrimRight(buf.Bytes(), "\x0a\x00"))
+// parseDate leniently parses the date strings found in RIFF chunks. Cameras
+// disagree on the format so all known layouts are tried. Dates without an
+// offset are local time of an unknown timezone, so they are in
+// exifdata.UnknownLocation.
+func parseDate(data []byte) (time.Time, error) {
+	// chunks are both line feed and null char terminated
+	val := strings.TrimSpace(string(bytes.TrimRight(data, "\x0a\x0d\x00")))
+	// collapse repeated whitespace (e.g. padded day of month)
+	val = strings.Join(strings.Fields(val), " ")
+	if exifdata.IsZeroDate(val) {
+		return time.Time{}, errors.Errorf("RIFF date is zeroed: %q", val)
+	}
 
-	// Parse string into Time
-	t, err := time.Parse(riffIDITDateLayout, val)
+	for _, layout := range riffDateLayouts {
+		t, err := time.ParseInLocation(layout, val, exifdata.UnknownLocation)
+		if err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.Errorf("unknown RIFF date format: %q", val)
+}
+
+// getTimeFromBext returns the OriginationDate and OriginationTime of the bext chunk. The date is "yyyy-mm-dd" and the
+// time "hh-mm-ss", but recorders are free to use any of the separators '-', '_', ':', ' ' and '.'. The time may be
+// missing. The date is local time of an unknown timezone, so it is in exifdata.UnknownLocation.
+func getTimeFromBext(data []byte) (time.Time, error) {
+	if len(data) < bextDateOffset+bextDateLen {
+		return time.Time{}, errors.New("RIFF bext chunk too short")
+	}
+	date := digits(data[bextDateOffset : bextDateOffset+bextDateLen])
+	if exifdata.IsZeroDate(date) {
+		return time.Time{}, errors.New("RIFF bext date is zeroed")
+	}
+	t, err := time.ParseInLocation("20060102", date, exifdata.UnknownLocation)
 	if err != nil {
-		return time.Time{}, err
+		return time.Time{}, errors.Errorf("unknown RIFF bext date format: %q", date)
+	}
+
+	if len(data) < bextDateOffset+bextDateLen+bextTimeLen {
+		return t, nil
+	}
+	clock := digits(data[bextDateOffset+bextDateLen : bextDateOffset+bextDateLen+bextTimeLen])
+	if tc, err := time.ParseInLocation("20060102150405", date+clock, exifdata.UnknownLocation); err == nil {
+		return tc, nil
+	}
+	return t, nil
+}
+
+// digits returns the decimal digits of the provided field
+func digits(field []byte) string {
+	var b strings.Builder
+	for _, c := range field {
+		if c >= '0' && c <= '9' {
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// getTimeFromStreamData returns the datetime from EXIF metadata in the strd
+// chunk. Some cameras embed a complete EXIF block while others write an AVIF
+// header followed by a bare little-endian IFD.
+func getTimeFromStreamData(data []byte) (time.Time, error) {
+	if !bytes.HasPrefix(data, avifHeader) {
+		t, err := exifdata.GetTimeFromBytes(data)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return t, nil
+	}
+	return getTimeFromAVIFIfd(data)
+}
+
+// getTimeFromAVIFIfd parses the little-endian IFD that follows the 8 byte AVIF header looking for the
+// DateTimeOriginal, DateTimeDigitized or DateTime tags in it or its EXIF sub-IFD. Cameras disagree on whether value
+// offsets are relative to the start of the chunk or to the IFD, so both are tried.
+// Ref: https://exiftool.org/TagNames/RIFF.html#AVIF
+func getTimeFromAVIFIfd(data []byte) (time.Time, error) {
+	const ifdStart = 8
+
+	for _, base := range []int{0, ifdStart} {
+		root := parseIfd(data, ifdStart, base)
+		ifds := []map[uint16][]byte{root}
+		// Exif sub-IFD pointers
+		for _, tag := range []uint16{0x0003, 0x8769} {
+			if ptr, ok := root[tag]; ok && len(ptr) == 4 {
+				ifds = append(ifds, parseIfd(data, base+int(binary.LittleEndian.Uint32(ptr)), base))
+			}
+		}
+
+		// DateTimeOriginal, DateTimeDigitized and DateTime
+		for _, tag := range []uint16{0x9003, 0x9004, 0x0132} {
+			for _, ifd := range ifds {
+				value, ok := ifd[tag]
+				if !ok {
+					continue
+				}
+				if t, err := parseDate(value); err == nil {
+					return t, nil
+				}
+			}
+		}
+	}
+	return time.Time{}, errors.New("could not find known date tags in AVIF stream data")
+}
+
+// parseIfd returns the raw value of each ASCII and LONG tag in the little-endian IFD at the provided offset. Values
+// that do not fit in an entry are read relative to base.
+func parseIfd(data []byte, offset, base int) map[uint16][]byte {
+	const (
+		entrySize = 12
+		asciiType = 2
+		longType  = 4
+	)
+
+	values := make(map[uint16][]byte)
+	if offset < 0 || offset+2 > len(data) {
+		return values
+	}
+	count := int(binary.LittleEndian.Uint16(data[offset:]))
+	for i := 0; i < count; i++ {
+		entry := offset + 2 + i*entrySize
+		if entry+entrySize > len(data) {
+			break
+		}
+		tag := binary.LittleEndian.Uint16(data[entry:])
+		size := int(binary.LittleEndian.Uint32(data[entry+4:]))
+		switch binary.LittleEndian.Uint16(data[entry+2:]) {
+		case asciiType:
+		case longType:
+			size *= 4
+		default:
+			continue
+		}
+		if size <= 4 {
+			values[tag] = data[entry+8 : entry+8+size]
+			continue
+		}
+		valueOffset := base + int(binary.LittleEndian.Uint32(data[entry+8:]))
+		if valueOffset+size <= len(data) {
+			values[tag] = data[valueOffset : valueOffset+size]
+		}
 	}
-	return t.UTC(), nil
+	return values
 }
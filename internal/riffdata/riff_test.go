@@ -0,0 +1,173 @@
+package riffdata
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dtrejod/goexif/internal/exifdata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chunk returns an encoded RIFF chunk with the provided id and data
+func chunk(id string, data ...[]byte) []byte {
+	payload := bytes.Join(data, nil)
+	out := append([]byte(id), binary.LittleEndian.AppendUint32(nil, uint32(len(payload)))...)
+	out = append(out, payload...)
+	if len(payload)%2 == 1 {
+		out = append(out, 0)
+	}
+	return out
+}
+
+// list returns an encoded RIFF LIST chunk with the provided list type and chunks
+func list(listType string, chunks ...[]byte) []byte {
+	return chunk("LIST", append([][]byte{[]byte(listType)}, chunks...)...)
+}
+
+// writeAVI writes an AVI file with the provided chunks and returns its path
+func writeAVI(t *testing.T, chunks ...[]byte) string {
+	t.Helper()
+	data := chunk("RIFF", append([][]byte{[]byte("AVI ")}, chunks...)...)
+	path := filepath.Join(t.TempDir(), "video.avi")
+	require.NoError(t, os.WriteFile(path, data, 0644))
+	return path
+}
+
+// avifStreamData returns strd chunk data with an AVIF header and a DateTimeOriginal tag
+func avifStreamData(date string) []byte {
+	value := append([]byte(date), 0)
+	ifd := binary.LittleEndian.AppendUint16(nil, 1)
+	ifd = binary.LittleEndian.AppendUint16(ifd, 0x9003)
+	ifd = binary.LittleEndian.AppendUint16(ifd, 2)
+	ifd = binary.LittleEndian.AppendUint32(ifd, uint32(len(value)))
+	// value follows the 2 byte count, 12 byte entry and 4 byte next IFD offset
+	ifd = binary.LittleEndian.AppendUint32(ifd, 8+2+12+4)
+	ifd = binary.LittleEndian.AppendUint32(ifd, 0)
+	return append(append([]byte("AVIF\x08\x00\x00\x00"), ifd...), value...)
+}
+
+func TestGetTime(t *testing.T) {
+	expected := time.Date(2005, 8, 12, 12, 0, 0, 0, exifdata.UnknownLocation)
+
+	for _, tc := range []struct {
+		name   string
+		chunks [][]byte
+	}{
+		{"IDIT ansic", [][]byte{list("hdrl", chunk("IDIT", []byte("Fri Aug 12 12:00:00 2005\n\x00")))}},
+		{"IDIT padded ansic", [][]byte{list("hdrl", chunk("IDIT", []byte("Fri Aug  12 12:00:00 2005\n\x00")))}},
+		{"IDIT exif", [][]byte{list("hdrl", chunk("IDIT", []byte("2005:08:12 12:00:00\x00")))}},
+		{"IDIT iso8601", [][]byte{list("hdrl", chunk("IDIT", []byte("2005-08-12T12:00:00")))}},
+		{"INFO ICRD", [][]byte{list("INFO", chunk("ICRD", []byte("2005-08-12 12:00:00\x00")))}},
+		{"strd AVIF", [][]byte{list("hdrl", list("strl", chunk("strd", avifStreamData("2005:08:12 12:00:00"))))}},
+		{"IDIT preferred over ICRD", [][]byte{
+			list("INFO", chunk("ICRD", []byte("2001-01-01"))),
+			list("hdrl", chunk("IDIT", []byte("2005:08:12 12:00:00"))),
+		}},
+		{"invalid IDIT falls back to ICRD", [][]byte{
+			list("hdrl", chunk("IDIT", []byte("not a date"))),
+			list("INFO", chunk("ICRD", []byte("2005/08/12 12:00:00"))),
+		}},
+		{"zeroed IDIT falls back to ICRD", [][]byte{
+			list("hdrl", chunk("IDIT", []byte("0000:00:00 00:00:00\x00"))),
+			list("INFO", chunk("ICRD", []byte("2005-08-12 12:00:00"))),
+		}},
+		{"movi list is skipped", [][]byte{
+			list("movi", chunk("IDIT", []byte("2001-01-01"))),
+			list("INFO", chunk("ICRD", []byte("2005-08-12T12:00:00"))),
+		}},
+		{"IDIT stops the scan", [][]byte{
+			list("hdrl", chunk("IDIT", []byte("2005:08:12 12:00:00"))),
+			// reading the oversized chunk would fail
+			list("INFO", chunk("ICRD", make([]byte, maxChunkLen+1))),
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := GetTime(writeAVI(t, tc.chunks...))
+			require.NoError(t, err)
+			assert.Equal(t, expected, actual)
+		})
+	}
+
+	t.Run("with offset", func(t *testing.T) {
+		actual, err := GetTime(writeAVI(t, list("INFO", chunk("ICRD", []byte("2005-08-12T12:00:00+09:00")))))
+		require.NoError(t, err)
+		assert.True(t, time.Date(2005, 8, 12, 3, 0, 0, 0, time.UTC).Equal(actual))
+		assert.False(t, exifdata.IsTimezoneUnknown(actual))
+	})
+
+	t.Run("without dates", func(t *testing.T) {
+		_, err := GetTime(writeAVI(t, list("hdrl", chunk("avih", make([]byte, 56)))))
+		assert.Error(t, err)
+	})
+}
+
+// bextChunk returns a bext chunk with the provided OriginationDate and OriginationTime
+func bextChunk(date, clock string) []byte {
+	data := make([]byte, bextDateOffset, bextDateOffset+bextDateLen+bextTimeLen+8+2+64+190)
+	data = append(data, date...)
+	data = append(data, clock...)
+	// TimeReference, Version, UMID and Reserved follow the origination time
+	return chunk("bext", data, make([]byte, 8+2+64+190))
+}
+
+func TestGetTimeBext(t *testing.T) {
+	fmtChunk := chunk("fmt ", make([]byte, 16))
+	dataChunk := chunk("data", make([]byte, 64))
+
+	for _, tc := range []struct {
+		name     string
+		chunks   [][]byte
+		expected time.Time
+		wantErr  bool
+	}{
+		{
+			name:     "date and time",
+			chunks:   [][]byte{bextChunk("2023-10-01", "12:30:45"), fmtChunk, dataChunk},
+			expected: time.Date(2023, 10, 1, 12, 30, 45, 0, exifdata.UnknownLocation),
+		},
+		{
+			name:     "other separators",
+			chunks:   [][]byte{fmtChunk, bextChunk("2023_10.01", "12-30 45"), dataChunk},
+			expected: time.Date(2023, 10, 1, 12, 30, 45, 0, exifdata.UnknownLocation),
+		},
+		{
+			name:     "missing time",
+			chunks:   [][]byte{bextChunk("2023-10-01", "\x00\x00\x00\x00\x00\x00\x00\x00"), dataChunk},
+			expected: time.Date(2023, 10, 1, 0, 0, 0, 0, exifdata.UnknownLocation),
+		},
+		{
+			name:     "preferred over ICRD",
+			chunks:   [][]byte{list("INFO", chunk("ICRD", []byte("2001-01-01"))), bextChunk("2023-10-01", "12:30:45")},
+			expected: time.Date(2023, 10, 1, 12, 30, 45, 0, exifdata.UnknownLocation),
+		},
+		{
+			name:    "zeroed date",
+			chunks:  [][]byte{bextChunk("0000-00-00", "00:00:00"), dataChunk},
+			wantErr: true,
+		},
+		{
+			name:    "missing date",
+			chunks:  [][]byte{bextChunk("\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00", "12:30:45"), dataChunk},
+			wantErr: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			data := chunk("RIFF", append([][]byte{[]byte("WAVE")}, tc.chunks...)...)
+			path := filepath.Join(t.TempDir(), "audio.wav")
+			require.NoError(t, os.WriteFile(path, data, 0644))
+
+			actual, err := GetTime(path)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package riffdata
+
+import (
+	"io"
+	"os"
+
+	"github.com/dtrejod/goexif/internal/exifdata"
+	"github.com/go-errors/errors"
+	"golang.org/x/image/riff"
+)
+
+var (
+	// webp is the RIFF form type of WebP media
+	// Ref: https://developers.google.com/speed/webp/docs/riff_container
+	webp = riff.FourCC{'W', 'E', 'B', 'P'}
+	// exifChunk is the WebP chunk holding the EXIF metadata. XMP metadata is in the "XMP " chunk, which is found by
+	// scanning for the XMP packet.
+	exifChunk = riff.FourCC{'E', 'X', 'I', 'F'}
+)
+
+// GetWebPExif returns the EXIF block of the WebP media referenced in the provided path
+func GetWebPExif(path string) (exifdata.Block, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	formType, r, err := riff.NewReader(f)
+	if err != nil {
+		return nil, errors.WrapPrefix(err, "could not open file with RIFF reader", 0)
+	}
+	if formType != webp {
+		return nil, errors.Errorf("unexpected RIFF form type: %q", formType[:])
+	}
+
+	for {
+		chunkID, chunkLen, chunkData, err := r.Next()
+		if err == io.EOF {
+			return nil, errors.New("could not find EXIF chunk in WebP media")
+		}
+		if err != nil {
+			return nil, errors.WrapPrefix(err, "unexpected error reading WebP chunks", 0)
+		}
+		if chunkID == exifChunk {
+			return readChunk(chunkData, chunkLen)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package riffdata
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dsoprea/go-exif/v3"
+	exifcommon "github.com/dsoprea/go-exif/v3/common"
+	"github.com/dtrejod/goexif/internal/exifdata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// encodeExif returns an EXIF block with the provided DateTimeOriginal
+func encodeExif(t *testing.T, date string) []byte {
+	t.Helper()
+
+	im, err := exifcommon.NewIfdMappingWithStandard()
+	require.NoError(t, err)
+	rootIb := exif.NewIfdBuilder(im, exif.NewTagIndex(), exifcommon.IfdStandardIfdIdentity, binary.LittleEndian)
+	ib, err := exif.GetOrCreateIbFromRootIb(rootIb, "IFD/Exif")
+	require.NoError(t, err)
+	require.NoError(t, ib.AddStandardWithName("DateTimeOriginal", date))
+
+	data, err := exif.NewIfdByteEncoder().EncodeToExif(rootIb)
+	require.NoError(t, err)
+	return data
+}
+
+// writeWebP writes a WebP file with the provided chunks and returns its path
+func writeWebP(t *testing.T, formType string, chunks ...[]byte) string {
+	t.Helper()
+	data := chunk("RIFF", append([][]byte{[]byte(formType)}, chunks...)...)
+	path := filepath.Join(t.TempDir(), "image.webp")
+	require.NoError(t, os.WriteFile(path, data, 0644))
+	return path
+}
+
+func TestGetWebPExif(t *testing.T) {
+	vp8x := chunk("VP8X", make([]byte, 10))
+	vp8l := chunk("VP8L", []byte{0x2f, 0x00, 0x00, 0x00, 0x00})
+
+	t.Run("exif chunk", func(t *testing.T) {
+		block, err := GetWebPExif(writeWebP(t, "WEBP", vp8x, vp8l, chunk("EXIF", encodeExif(t, "2023:10:01 12:00:00"))))
+		require.NoError(t, err)
+
+		actual, err := block.GetDateTimeOriginal()
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2023, 10, 1, 12, 0, 0, 0, exifdata.UnknownLocation), actual)
+	})
+
+	t.Run("exif chunk with identifier", func(t *testing.T) {
+		exifData := append([]byte("Exif\x00\x00"), encodeExif(t, "2023:10:01 12:00:00")...)
+		block, err := GetWebPExif(writeWebP(t, "WEBP", vp8x, vp8l, chunk("EXIF", exifData)))
+		require.NoError(t, err)
+
+		actual, err := block.GetTime()
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2023, 10, 1, 12, 0, 0, 0, exifdata.UnknownLocation), actual)
+	})
+
+	t.Run("without exif chunk", func(t *testing.T) {
+		_, err := GetWebPExif(writeWebP(t, "WEBP", vp8l))
+		assert.Error(t, err)
+	})
+
+	t.Run("not webp", func(t *testing.T) {
+		_, err := GetWebPExif(writeWebP(t, "AVI ", chunk("EXIF", encodeExif(t, "2023:10:01 12:00:00"))))
+		assert.Error(t, err)
+	})
+}
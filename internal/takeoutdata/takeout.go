@@ -0,0 +1,225 @@
+package takeoutdata
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dtrejod/goexif/internal/dircache"
+	"github.com/dtrejod/goexif/internal/geotz"
+)
+
+const (
+	sidecarExt = ".json"
+	// supplementalMetadata is the infix newer exports add to sidecar names, e.g.
+	// IMG_1234.jpg.supplemental-metadata.json. It is truncated like the rest of the name.
+	supplementalMetadata = ".supplemental-metadata"
+	// maxSidecarNameLen is the length Takeout truncates sidecar filenames to
+	maxSidecarNameLen = 51
+	// maxCachedDirs is the number of directory listings kept for finding truncated sidecars
+	maxCachedDirs = 16
+)
+
+var (
+	// duplicateSuffix matches the counter Takeout appends to media with the same name, e.g. IMG_1234(1).jpg. The
+	// sidecar has the counter after the extension instead, e.g. IMG_1234.jpg(1).json.
+	duplicateSuffix = regexp.MustCompile(`^(.+)(\(\d+\))$`)
+
+	// listings caches the sidecar filenames of each directory, so the directory of an export is read once instead of
+	// once per media file
+	listings = dircache.New(maxCachedDirs, listSidecars)
+
+	errNoSidecar     = errors.New("could not find Google Takeout sidecar")
+	errNoPhotoTaken  = errors.New("could not find photoTakenTime in Google Takeout sidecar")
+	errNoCoordinates = errors.New("could not find coordinates in Google Takeout sidecar")
+)
+
+// Metadata is the metadata Google Takeout exports in a JSON sidecar next to each media file
+// Ref: https://support.google.com/accounts/answer/3024190
+type Metadata struct {
+	Title          string    `json:"title"`
+	PhotoTakenTime timestamp `json:"photoTakenTime"`
+	CreationTime   timestamp `json:"creationTime"`
+	GeoData        geoData   `json:"geoData"`
+	GeoDataExif    geoData   `json:"geoDataExif"`
+}
+
+type timestamp struct {
+	// Timestamp is the Unix EPOCH time in seconds as a string
+	Timestamp string `json:"timestamp"`
+}
+
+type geoData struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// GetTime returns the photoTakenTime from the Google Takeout sidecar of the media referenced in the provided path.
+// When the sidecar has coordinates, then the returned time is in the timezone found at those coordinates. Otherwise
+// the time is in UTC.
+func GetTime(path string) (time.Time, error) {
+	md, err := GetMetadata(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	t, err := md.PhotoTakenTime.time()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	loc, err := md.location()
+	if err != nil {
+		return t, nil
+	}
+	return t.In(loc), nil
+}
+
+// GetMetadata returns the parsed Google Takeout sidecar of the media referenced in the provided path
+func GetMetadata(path string) (Metadata, error) {
+	sidecar, err := FindSidecar(path)
+	if err != nil {
+		return Metadata{}, err
+	}
+
+	data, err := os.ReadFile(sidecar)
+	if err != nil {
+		return Metadata{}, err
+	}
+	var md Metadata
+	if err := json.Unmarshal(data, &md); err != nil {
+		return Metadata{}, err
+	}
+	return md, nil
+}
+
+// FindSidecar returns the path of the Google Takeout sidecar of the media referenced in the provided path. Takeout
+// names sidecars after the full media filename (IMG_1234.jpg.json), but truncates long names (IMG_1234.jp.json), moves
+// duplicate counters (IMG_1234(1).jpg has IMG_1234.jpg(1).json) and newer exports add a supplemental-metadata infix.
+func FindSidecar(path string) (string, error) {
+	dir, name := filepath.Split(path)
+	for _, candidate := range sidecarNames(name) {
+		sidecar := filepath.Join(dir, candidate)
+		if f, err := os.Stat(sidecar); err == nil && !f.IsDir() {
+			return sidecar, nil
+		}
+	}
+
+	// fallback to scanning the directory for sidecars truncated to any length
+	sidecars, err := listings.Get(filepath.Dir(path))
+	if err != nil {
+		return "", err
+	}
+	var match string
+	for _, sidecar := range sidecars {
+		if !IsSidecarFor(sidecar, name) {
+			continue
+		}
+		// prefer the least truncated sidecar
+		if len(sidecar) > len(match) {
+			match = sidecar
+		}
+	}
+	if match == "" {
+		return "", errNoSidecar
+	}
+	return filepath.Join(dir, match), nil
+}
+
+// listSidecars returns the names of the JSON files in the provided directory
+func listSidecars(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.EqualFold(filepath.Ext(e.Name()), sidecarExt) {
+			names = append(names, e.Name())
+		}
+	}
+	return names, nil
+}
+
+// IsSidecarFor returns true if the provided sidecar filename is the, possibly truncated, Google Takeout sidecar of the
+// provided media filename
+func IsSidecarFor(sidecarName, mediaName string) bool {
+	if !strings.EqualFold(filepath.Ext(sidecarName), sidecarExt) {
+		return false
+	}
+	stem := sidecarName[:len(sidecarName)-len(sidecarExt)]
+	if stem == "" {
+		return false
+	}
+
+	// IMG_1234.jpg.supplemental-met.json
+	if rest, ok := strings.CutPrefix(stem, mediaName); ok {
+		return rest == "" || strings.HasPrefix(supplementalMetadata, rest)
+	}
+
+	// IMG_1234.jp.json must still cover the media name without its extension, unless the whole name was truncated
+	if !strings.HasPrefix(mediaName, stem) {
+		return false
+	}
+	base := strings.TrimSuffix(mediaName, filepath.Ext(mediaName))
+	return len(stem) >= len(base) || len(sidecarName) == maxSidecarNameLen
+}
+
+// sidecarNames returns the sidecar filenames Google Takeout uses for the provided media filename in order of
+// preference
+func sidecarNames(mediaName string) []string {
+	ext := filepath.Ext(mediaName)
+	base := strings.TrimSuffix(mediaName, ext)
+
+	stems := []string{mediaName, mediaName + supplementalMetadata}
+	counter := ""
+	if m := duplicateSuffix.FindStringSubmatch(base); m != nil {
+		stems = []string{m[1] + ext, m[1] + ext + supplementalMetadata}
+		counter = m[2]
+	}
+
+	names := make([]string, 0, len(stems))
+	for _, stem := range stems {
+		names = append(names, truncate(stem, maxSidecarNameLen-len(counter)-len(sidecarExt))+counter+sidecarExt)
+	}
+	// IMG_1234.json
+	if counter == "" {
+		names = append(names, base+sidecarExt)
+	}
+	return names
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
+
+func (t timestamp) time() (time.Time, error) {
+	if t.Timestamp == "" {
+		return time.Time{}, errNoPhotoTaken
+	}
+	sec, err := strconv.ParseInt(t.Timestamp, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(sec, 0).UTC(), nil
+}
+
+// location returns the timezone location at the coordinates of the media. Takeout writes 0,0 when the media has no
+// coordinates.
+func (md Metadata) location() (*time.Location, error) {
+	for _, g := range []geoData{md.GeoData, md.GeoDataExif} {
+		if g.Latitude == 0 && g.Longitude == 0 {
+			continue
+		}
+		return geotz.Location(g.Latitude, g.Longitude)
+	}
+	return nil, errNoCoordinates
+}
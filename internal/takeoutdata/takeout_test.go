@@ -0,0 +1,120 @@
+package takeoutdata
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dtrejod/goexif/internal/dircache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sidecar = `{
+  "title": "IMG_1234.jpg",
+  "photoTakenTime": {"timestamp": "1593213566", "formatted": "Jun 26, 2020, 11:19:26 PM UTC"},
+  "creationTime": {"timestamp": "1600000000", "formatted": "Sep 13, 2020, 12:26:40 PM UTC"},
+  "geoData": {"latitude": 0.0, "longitude": 0.0, "altitude": 0.0},
+  "geoDataExif": {"latitude": 0.0, "longitude": 0.0, "altitude": 0.0}
+}`
+
+func writeFiles(t *testing.T, names ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, name := range names {
+		data := []byte("not json")
+		if filepath.Ext(name) == sidecarExt {
+			data = []byte(sidecar)
+		}
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0644))
+	}
+	return dir
+}
+
+func TestGetTime(t *testing.T) {
+	expected := time.Date(2020, 6, 26, 23, 19, 26, 0, time.UTC)
+
+	t.Run("without coordinates", func(t *testing.T) {
+		dir := writeFiles(t, "IMG_1234.jpg", "IMG_1234.jpg.json")
+
+		actual, err := GetTime(filepath.Join(dir, "IMG_1234.jpg"))
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("with coordinates", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "IMG_1234.jpg")
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "IMG_1234.jpg.json"), []byte(`{
+  "photoTakenTime": {"timestamp": "1593213566"},
+  "geoData": {"latitude": 35.6812, "longitude": 139.7671}
+}`), 0644))
+
+		actual, err := GetTime(path)
+		require.NoError(t, err)
+		assert.True(t, expected.Equal(actual))
+		assert.Equal(t, "Asia/Tokyo", actual.Location().String())
+	})
+
+	t.Run("without sidecar", func(t *testing.T) {
+		dir := writeFiles(t, "IMG_1234.jpg", "IMG_5678.jpg.json")
+
+		_, err := GetTime(filepath.Join(dir, "IMG_1234.jpg"))
+		assert.Error(t, err)
+	})
+}
+
+func TestFindSidecar(t *testing.T) {
+	longName := "Screenshot_2020-06-26-23-19-26-123_com.example.app.jpg"
+
+	for name, tc := range map[string]struct {
+		media   string
+		sidecar string
+	}{
+		"full name":              {"IMG_1234.jpg", "IMG_1234.jpg.json"},
+		"without extension":      {"IMG_1234.jpg", "IMG_1234.json"},
+		"truncated extension":    {"IMG_1234.jpg", "IMG_1234.jp.json"},
+		"truncated long name":    {longName, longName[:46] + ".json"},
+		"duplicate":              {"IMG_1234(1).jpg", "IMG_1234.jpg(1).json"},
+		"supplemental metadata":  {"IMG_1234.jpg", "IMG_1234.jpg.supplemental-metadata.json"},
+		"truncated supplemental": {"IMG_1234.jpg", "IMG_1234.jpg.supplemental-met.json"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := writeFiles(t, tc.media, tc.sidecar, "IMG_123.jpg.json", "IMG_12345.jpg.json")
+
+			actual, err := FindSidecar(filepath.Join(dir, tc.media))
+			require.NoError(t, err)
+			assert.Equal(t, filepath.Join(dir, tc.sidecar), actual)
+		})
+	}
+}
+
+func TestFindSidecarListsDirectoryOnce(t *testing.T) {
+	saved := listings
+	t.Cleanup(func() {
+		listings = saved
+	})
+	var reads int
+	listings = dircache.New(maxCachedDirs, func(dir string) ([]string, error) {
+		reads++
+		return listSidecars(dir)
+	})
+
+	dir := writeFiles(t, "IMG_1234.jpg", "IMG_1234.jp.json", "IMG_5678.jpg", "IMG_5678.jp.json")
+	for _, name := range []string{"IMG_1234", "IMG_5678"} {
+		actual, err := FindSidecar(filepath.Join(dir, name+".jpg"))
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(dir, name+".jp.json"), actual)
+	}
+	assert.Equal(t, 1, reads)
+}
+
+func TestIsSidecarFor(t *testing.T) {
+	assert.True(t, IsSidecarFor("IMG_1234.jp.json", "IMG_1234.jpg"))
+	assert.True(t, IsSidecarFor("IMG_1234.JSON", "IMG_1234.jpg"))
+	assert.False(t, IsSidecarFor("IMG_123.json", "IMG_1234.jpg"))
+	assert.False(t, IsSidecarFor("IMG_1234.jpg.xmp", "IMG_1234.jpg"))
+	assert.False(t, IsSidecarFor("IMG_1234.jpg.edited.json", "IMG_1234.jpg"))
+	assert.False(t, IsSidecarFor(".json", "IMG_1234.jpg"))
+}
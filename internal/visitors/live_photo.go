@@ -0,0 +1,56 @@
+package visitors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/dtrejod/goexif/internal/exifdata"
+	"github.com/dtrejod/goexif/internal/mediatype"
+	"github.com/dtrejod/goexif/internal/moovdata"
+)
+
+// ErrNotLivePhoto is returned by the LivePhoto visitor for media that cannot be part of a Live Photo
+var ErrNotLivePhoto = errors.New("media is not part of a live photo")
+
+// LivePhoto is the return type from the LivePhoto visitor
+type LivePhoto struct {
+	// ContentIdentifier is shared by the image and the video of an Apple Live Photo
+	ContentIdentifier string
+	// IsVideo is true for the video of the Live Photo
+	IsVideo bool
+}
+
+type livePhoto struct{}
+
+// NewLivePhoto is a mediatype visitor that will get the Apple Live Photo content identifier of a media file. The image
+// records it in its Apple MakerNote and the video in its QuickTime metadata.
+func NewLivePhoto(_ context.Context) mediatype.VisitorFunc[LivePhoto] {
+	return &livePhoto{}
+}
+
+func (l *livePhoto) Visit(_ context.Context, format mediatype.Registration, path string) (LivePhoto, error) {
+	switch format.Media {
+	case mediatype.JPEG{}, mediatype.HEIF{}:
+		return getLivePhotoImage(path)
+	case mediatype.QTFF{}, mediatype.MP4{}:
+		return getLivePhotoVideo(path)
+	}
+	return LivePhoto{}, ErrNotLivePhoto
+}
+
+func getLivePhotoImage(path string) (LivePhoto, error) {
+	id, err := exifdata.GetContentIdentifier(path)
+	if err != nil {
+		return LivePhoto{}, fmt.Errorf("%w: %v", ErrNotLivePhoto, err)
+	}
+	return LivePhoto{ContentIdentifier: id}, nil
+}
+
+func getLivePhotoVideo(path string) (LivePhoto, error) {
+	id, err := moovdata.GetContentIdentifier(path)
+	if err != nil {
+		return LivePhoto{}, fmt.Errorf("%w: %v", ErrNotLivePhoto, err)
+	}
+	return LivePhoto{ContentIdentifier: id, IsVideo: true}, nil
+}
@@ -0,0 +1,40 @@
+package visitors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dtrejod/goexif/internal/mediatype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLivePhoto(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("pair", func(t *testing.T) {
+		var ids []string
+		for path, isVideo := range map[string]bool{"./testdata/live.jpg": false, "./testdata/live.mov": true} {
+			srcMedia, err := mediatype.NewFormat(path, false)
+			require.NoError(t, err)
+
+			visitor := mediatype.FormatWithVisitor[LivePhoto](srcMedia)
+			actual, err := visitor.Accept(ctx, NewLivePhoto(ctx))
+			require.NoError(t, err, path)
+			assert.Equal(t, isVideo, actual.IsVideo, path)
+			ids = append(ids, actual.ContentIdentifier)
+		}
+		assert.Equal(t, []string{"8E0C8AA8-5D4E-4B0F-9E6C-3F1B2E1F6E9A", "8E0C8AA8-5D4E-4B0F-9E6C-3F1B2E1F6E9A"}, ids)
+	})
+
+	for _, path := range []string{"./testdata/white.png", "./testdata/ispng.jpg"} {
+		t.Run(path, func(t *testing.T) {
+			srcMedia, err := mediatype.NewFormat(path, false)
+			assert.NoError(t, err)
+
+			visitor := mediatype.FormatWithVisitor[LivePhoto](srcMedia)
+			_, err = visitor.Accept(ctx, NewLivePhoto(ctx))
+			assert.ErrorIs(t, err, ErrNotLivePhoto)
+		})
+	}
+}
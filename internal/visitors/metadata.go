@@ -2,16 +2,14 @@ package visitors
 
 import (
 	"context"
-	"os"
+	"fmt"
 	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/dtrejod/goexif/internal/exifdata"
+	"github.com/dtrejod/goexif/internal/dateresolver"
 	"github.com/dtrejod/goexif/internal/mediatype"
-	"github.com/dtrejod/goexif/internal/moovdata"
-	"github.com/dtrejod/goexif/internal/riffdata"
 )
 
 const (
@@ -20,7 +18,7 @@ const (
 
 type mediaMetadataFilename struct {
 	outDir                  *string
-	useLastModifiedDate     bool
+	resolver                *dateresolver.Resolver
 	timestampAsFilename     bool
 	useOutputMagicSignature bool
 }
@@ -28,91 +26,66 @@ type mediaMetadataFilename struct {
 // MediaMetadata is the return type from the MediaMetadataFilename visitor
 type MediaMetadata struct {
 	// OutPath is an appropriate new output filename for the provided mediatype format.
-	OutPath   string
+	OutPath string
+	// Timestamp is the time the media was taken including any sub-second precision found in the metadata.
 	Timestamp time.Time
+	// DateSource is the name of the date source the Timestamp was found in, e.g. exif-original.
+	DateSource string
 }
 
-// NewMediaMetadataFilename is a mediatype visitor that will generate metadata info on a provided media file
-// - useLastModifiedDate: Fallback to using the last modified date if no EXIF data exists on the media
-// - timestampAsFilename: Use the Unix EPOCH time as the output file name.
+// NewMediaMetadataFilename is a mediatype visitor that will generate metadata info on a provided media file.
+// - resolver: The date sources used to date the media. The dateresolver.DefaultSources are used when nil.
+// - timestampAsFilename: Use the Unix EPOCH time, including any milliseconds, as the output file name.
 // - useOutputMagicSignature: Use the identified mediatype Ext as the extension of the output filename
-// TODO(dtrejo): Rename useLastModifiedDate to fallbackToLastModifiedDate to
-// better describe what this variable actually does.
 func NewMediaMetadataFilename(
 	_ context.Context,
 	outDir *string,
-	useLastModifiedDate,
+	resolver *dateresolver.Resolver,
 	timestampAsFilename,
 	useOutputMagicSignature bool,
 ) mediatype.VisitorFunc[MediaMetadata] {
+	if resolver == nil {
+		// the default sources are all known
+		resolver, _ = dateresolver.NewFromNames(dateresolver.DefaultSources, nil)
+	}
 	return &mediaMetadataFilename{
 		outDir:                  outDir,
-		useLastModifiedDate:     useLastModifiedDate,
+		resolver:                resolver,
 		timestampAsFilename:     timestampAsFilename,
 		useOutputMagicSignature: useOutputMagicSignature,
 	}
 }
 
-func (e *mediaMetadataFilename) VisitJPEG(ctx context.Context, image mediatype.JPEG) (MediaMetadata, error) {
-	return e.getTimeMetadataWithFunc(ctx, image.Path, exifdata.GetTime, image.Ext())
-}
-
-// VisitPNG implements VisitorFunc
-// EXIF extension was adopted for PNG in 2017
-// http://ftp-osl.osuosl.org/pub/libpng/documents/pngext-1.5.0.html#C.eXIf
-func (e *mediaMetadataFilename) VisitPNG(ctx context.Context, image mediatype.PNG) (MediaMetadata, error) {
-	return e.getTimeMetadataWithFunc(ctx, image.Path, exifdata.GetTime, image.Ext())
-}
-
-func (e *mediaMetadataFilename) VisitHEIF(ctx context.Context, image mediatype.HEIF) (MediaMetadata, error) {
-	return e.getTimeMetadataWithFunc(ctx, image.Path, exifdata.GetTime, image.Ext())
-}
-
-func (e *mediaMetadataFilename) VisitTIFF(ctx context.Context, image mediatype.TIFF) (MediaMetadata, error) {
-	return e.getTimeMetadataWithFunc(ctx, image.Path, exifdata.GetTime, image.Ext())
-}
-
-func (e *mediaMetadataFilename) VisitQTFF(ctx context.Context, image mediatype.QTFF) (MediaMetadata, error) {
-	return e.getTimeMetadataWithFunc(ctx, image.Path, moovdata.GetTime, image.Ext())
-}
-
-func (e *mediaMetadataFilename) VisitMP4(ctx context.Context, image mediatype.MP4) (MediaMetadata, error) {
-	return e.getTimeMetadataWithFunc(ctx, image.Path, moovdata.GetTime, image.Ext())
-}
-
-func (e *mediaMetadataFilename) VisitAVI(ctx context.Context, image mediatype.AVI) (MediaMetadata, error) {
-	return e.getTimeMetadataWithFunc(ctx, image.Path, riffdata.GetTime, image.Ext())
-}
-
-func (e *mediaMetadataFilename) Visit3PG(ctx context.Context, image mediatype.GPP) (MediaMetadata, error) {
-	return e.getTimeMetadataWithFunc(ctx, image.Path, moovdata.GetTime, image.Ext())
-}
-
-func (e *mediaMetadataFilename) Visit3G2(ctx context.Context, image mediatype.GPP2) (MediaMetadata, error) {
-	return e.getTimeMetadataWithFunc(ctx, image.Path, moovdata.GetTime, image.Ext())
+// Visit implements VisitorFunc. The media is dated by the date extractor registered for its format.
+func (e *mediaMetadataFilename) Visit(
+	ctx context.Context,
+	format mediatype.Registration,
+	path string,
+) (MediaMetadata, error) {
+	return e.getTimeMetadata(ctx, path, format.Dates, format.Media.Ext())
 }
 
-func (e *mediaMetadataFilename) getTimeMetadataWithFunc(
+func (e *mediaMetadataFilename) getTimeMetadata(
 	ctx context.Context,
 	srcPath string,
-	tsFunc func(string) (time.Time, error),
+	dates mediatype.DateExtractor,
 	cleanEXT string,
 ) (MediaMetadata, error) {
-	ts, err := tsFunc(srcPath)
+	ts, source, err := e.resolver.Resolve(dateresolver.Media{Path: srcPath, Dates: dates})
 	if err != nil {
-		ts, err = e.fallbackToModTime(srcPath, err)
-		if err != nil {
-			return MediaMetadata{}, err
-		}
+		return MediaMetadata{}, err
 	}
-	outFile, err := e.getOutputFile(ctx, srcPath, cleanEXT, ts.UTC())
+	// bucket media by the wall clock date it was taken on. Timezone aware
+	// timestamps keep their local date instead of shifting into UTC.
+	outFile, err := e.getOutputFile(ctx, srcPath, cleanEXT, ts)
 	if err != nil {
 		return MediaMetadata{}, err
 	}
 
 	return MediaMetadata{
-		OutPath:   outFile,
-		Timestamp: ts,
+		OutPath:    outFile,
+		Timestamp:  ts,
+		DateSource: source,
 	}, nil
 }
 
@@ -129,7 +102,7 @@ func (e *mediaMetadataFilename) getOutputFile(_ context.Context, srcPath, cleanE
 	}
 	outFilename := strings.TrimSuffix(filepath.Base(srcPath), filepath.Ext(srcPath))
 	if e.timestampAsFilename {
-		outFilename = strconv.FormatInt(ts.Unix(), 10)
+		outFilename = timestampFilename(ts)
 	}
 
 	outFilename = outFilename + ext
@@ -137,14 +110,12 @@ func (e *mediaMetadataFilename) getOutputFile(_ context.Context, srcPath, cleanE
 
 }
 
-func (e *mediaMetadataFilename) fallbackToModTime(srcPath string, origErr error) (time.Time, error) {
-	// on error, fallback to lastmodified if the option was specified
-	if e.useLastModifiedDate {
-		f, statErr := os.Stat(srcPath)
-		if statErr != nil {
-			return time.Time{}, origErr
-		}
-		return f.ModTime(), nil
+// timestampFilename returns the Unix EPOCH time as a filename. Media with sub-second precision, such as burst shots
+// taken within the same second, get the milliseconds appended (e.g. 1593213566_123) to keep their names unique.
+func timestampFilename(ts time.Time) string {
+	name := strconv.FormatInt(ts.Unix(), 10)
+	if ms := ts.Nanosecond() / int(time.Millisecond); ms > 0 {
+		name = fmt.Sprintf("%s_%03d", name, ms)
 	}
-	return time.Time{}, origErr
+	return name
 }
@@ -2,25 +2,44 @@ package visitors
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
 	"testing"
 	"time"
 
+	"github.com/dtrejod/goexif/internal/dateresolver"
+	"github.com/dtrejod/goexif/internal/exifdata"
+	"github.com/dtrejod/goexif/internal/mediaformats"
 	"github.com/dtrejod/goexif/internal/mediatype"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+func TestMain(m *testing.M) {
+	if err := mediaformats.Register(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
+
 func TestMetadataFilename(t *testing.T) {
 	ctx := context.Background()
+	// the test media is dated with the 2000-01-01 camera default
+	resolver := newResolver(t, dateresolver.DefaultSources)
 
 	t.Run("with default config", func(t *testing.T) {
 		expected := MediaMetadata{
-			OutPath:   "2000/01/01/white.png",
-			Timestamp: time.Date(2000, 01, 01, 0, 0, 0, 0, time.UTC),
+			OutPath:    "2000/01/01/white.png",
+			Timestamp:  time.Date(2000, 01, 01, 0, 0, 0, 0, exifdata.UnknownLocation),
+			DateSource: dateresolver.ExifOriginal,
 		}
 		srcMedia, err := mediatype.NewFormat("./testdata/white.png", false)
 		assert.NoError(t, err)
 
-		visitorFunc := NewMediaMetadataFilename(ctx, toPtr("."), false, false, false)
+		visitorFunc := NewMediaMetadataFilename(ctx, toPtr("."), resolver, false, false)
 		visitor := mediatype.FormatWithVisitor[MediaMetadata](srcMedia)
 		actual, err := visitor.Accept(ctx, visitorFunc)
 		assert.NoError(t, err)
@@ -34,7 +53,9 @@ func TestMetadataFilename(t *testing.T) {
 	//	srcMedia, err := mediatype.ID("./testdata/noexif.png", false)
 	//	assert.NoError(t, err)
 
-	//	visitorFunc := NewMediaMetadataFilename(ctx, toPtr("."), true, false, false)
+	//	modTimeResolver, err := dateresolver.NewFromNames(dateresolver.Defaults(false, false, true), nil)
+	//	assert.NoError(t, err)
+	//	visitorFunc := NewMediaMetadataFilename(ctx, toPtr("."), modTimeResolver, false, false)
 	//	visitor := mediatype.FormatWithVisitor[string](srcMedia)
 	//	actual, err := visitor.Accept(ctx, visitorFunc)
 	//	assert.NoError(t, err)
@@ -42,15 +63,110 @@ func TestMetadataFilename(t *testing.T) {
 	//	assert.Equal(t, expected, actual)
 	//})
 
+	t.Run("with xmp sidecar", func(t *testing.T) {
+		dir := t.TempDir()
+		data, err := os.ReadFile("./testdata/noexif.png")
+		assert.NoError(t, err)
+		srcPath := filepath.Join(dir, "noexif.png")
+		assert.NoError(t, os.WriteFile(srcPath, data, 0644))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "noexif.xmp"), []byte(`<x:xmpmeta xmlns:x="adobe:ns:meta/">
+ <rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+  <rdf:Description rdf:about="" xmlns:exif="http://ns.adobe.com/exif/1.0/" exif:DateTimeOriginal="2000-01-01T00:00:00"/>
+ </rdf:RDF>
+</x:xmpmeta>`), 0644))
+
+		expected := MediaMetadata{
+			OutPath:    "2000/01/01/noexif.png",
+			Timestamp:  time.Date(2000, 01, 01, 0, 0, 0, 0, exifdata.UnknownLocation),
+			DateSource: dateresolver.XMP,
+		}
+		srcMedia, err := mediatype.NewFormat(srcPath, false)
+		assert.NoError(t, err)
+
+		visitorFunc := NewMediaMetadataFilename(ctx, toPtr("."), resolver, false, false)
+		visitor := mediatype.FormatWithVisitor[MediaMetadata](srcMedia)
+		actual, err := visitor.Accept(ctx, visitorFunc)
+		assert.NoError(t, err)
+
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("with google takeout sidecar", func(t *testing.T) {
+		dir := t.TempDir()
+		data, err := os.ReadFile("./testdata/noexif.png")
+		assert.NoError(t, err)
+		srcPath := filepath.Join(dir, "noexif.png")
+		assert.NoError(t, os.WriteFile(srcPath, data, 0644))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "noexif.pn.json"),
+			[]byte(`{"title": "noexif.png", "photoTakenTime": {"timestamp": "1593213566"}}`), 0644))
+
+		expected := MediaMetadata{
+			OutPath:    "2020/06/26/noexif.png",
+			Timestamp:  time.Date(2020, 06, 26, 23, 19, 26, 0, time.UTC),
+			DateSource: dateresolver.Takeout,
+		}
+		srcMedia, err := mediatype.NewFormat(srcPath, false)
+		assert.NoError(t, err)
+
+		visitorFunc := NewMediaMetadataFilename(ctx, toPtr("."), resolver, false, false)
+		visitor := mediatype.FormatWithVisitor[MediaMetadata](srcMedia)
+		actual, err := visitor.Accept(ctx, visitorFunc)
+		assert.NoError(t, err)
+
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("with filename fallback", func(t *testing.T) {
+		dir := t.TempDir()
+		data, err := os.ReadFile("./testdata/noexif.png")
+		assert.NoError(t, err)
+		srcPath := filepath.Join(dir, "Screenshot 2021-03-04 at 10.11.12.png")
+		assert.NoError(t, os.WriteFile(srcPath, data, 0644))
+
+		expected := MediaMetadata{
+			OutPath:    "2021/03/04/Screenshot 2021-03-04 at 10.11.12.png",
+			Timestamp:  time.Date(2021, 03, 04, 10, 11, 12, 0, exifdata.UnknownLocation),
+			DateSource: dateresolver.Filename,
+		}
+		srcMedia, err := mediatype.NewFormat(srcPath, false)
+		assert.NoError(t, err)
+
+		filenameResolver := newResolver(t, dateresolver.Defaults(false, true, false))
+		visitorFunc := NewMediaMetadataFilename(ctx, toPtr("."), filenameResolver, false, false)
+		visitor := mediatype.FormatWithVisitor[MediaMetadata](srcMedia)
+		actual, err := visitor.Accept(ctx, visitorFunc)
+		assert.NoError(t, err)
+
+		assert.Equal(t, expected, actual)
+
+		// without the fallback the media has no date
+		visitorFunc = NewMediaMetadataFilename(ctx, toPtr("."), resolver, false, false)
+		_, err = visitor.Accept(ctx, visitorFunc)
+		assert.Error(t, err)
+	})
+
+	t.Run("with camera default date", func(t *testing.T) {
+		srcMedia, err := mediatype.NewFormat("./testdata/white.png", false)
+		assert.NoError(t, err)
+
+		defaultResolver, err := dateresolver.NewFromNames(dateresolver.DefaultSources, nil)
+		require.NoError(t, err)
+		visitorFunc := NewMediaMetadataFilename(ctx, toPtr("."), defaultResolver, false, false)
+		visitor := mediatype.FormatWithVisitor[MediaMetadata](srcMedia)
+		_, err = visitor.Accept(ctx, visitorFunc)
+		assert.ErrorIs(t, err, dateresolver.ErrInvalidDate)
+	})
+
 	t.Run("with timestamp as filename", func(t *testing.T) {
 		expected := MediaMetadata{
-			OutPath:   "2000/01/01/946684800.png",
-			Timestamp: time.Date(2000, 01, 01, 0, 0, 0, 0, time.UTC),
+			OutPath:    "2000/01/01/946684800.png",
+			Timestamp:  time.Date(2000, 01, 01, 0, 0, 0, 0, exifdata.UnknownLocation),
+			DateSource: dateresolver.ExifOriginal,
 		}
 		srcMedia, err := mediatype.NewFormat("./testdata/white.png", false)
 		assert.NoError(t, err)
 
-		visitorFunc := NewMediaMetadataFilename(ctx, toPtr("."), false, true, false)
+		visitorFunc := NewMediaMetadataFilename(ctx, toPtr("."), resolver, true, false)
 		visitor := mediatype.FormatWithVisitor[MediaMetadata](srcMedia)
 		actual, err := visitor.Accept(ctx, visitorFunc)
 		assert.NoError(t, err)
@@ -60,13 +176,14 @@ func TestMetadataFilename(t *testing.T) {
 
 	t.Run("with clean file extension", func(t *testing.T) {
 		expected := MediaMetadata{
-			OutPath:   "2000/01/01/ispng.png",
-			Timestamp: time.Date(2000, 01, 01, 0, 0, 0, 0, time.UTC),
+			OutPath:    "2000/01/01/ispng.png",
+			Timestamp:  time.Date(2000, 01, 01, 0, 0, 0, 0, exifdata.UnknownLocation),
+			DateSource: dateresolver.ExifOriginal,
 		}
 		srcMedia, err := mediatype.NewFormat("./testdata/ispng.jpg", true)
 		assert.NoError(t, err)
 
-		visitorFunc := NewMediaMetadataFilename(ctx, toPtr("."), false, false, true)
+		visitorFunc := NewMediaMetadataFilename(ctx, toPtr("."), resolver, false, true)
 		visitor := mediatype.FormatWithVisitor[MediaMetadata](srcMedia)
 		actual, err := visitor.Accept(ctx, visitorFunc)
 		assert.NoError(t, err)
@@ -75,6 +192,25 @@ func TestMetadataFilename(t *testing.T) {
 	})
 }
 
+func TestTimestampFilename(t *testing.T) {
+	ts := time.Date(2000, 01, 01, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, "946684800", timestampFilename(ts))
+	assert.Equal(t, "946684800_005", timestampFilename(ts.Add(5*time.Millisecond)))
+	assert.Equal(t, "946684800_123", timestampFilename(ts.Add(123456*time.Microsecond)))
+}
+
+// newResolver returns a resolver for the named sources whose validator only accepts the camera default date of the
+// test media on top of the default validator
+func newResolver(t *testing.T, names []string) *dateresolver.Resolver {
+	t.Helper()
+	r, err := dateresolver.NewFromNames(names, nil)
+	require.NoError(t, err)
+	v := dateresolver.NewValidator()
+	v.SuspiciousDates = slices.DeleteFunc(slices.Clone(v.SuspiciousDates), dateresolver.CameraResetDate.Equal)
+	r.SetValidator(v)
+	return r
+}
+
 func toPtr[T any](v T) *T {
 	return &v
 }
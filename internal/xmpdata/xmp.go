@@ -0,0 +1,275 @@
+package xmpdata
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	mp4 "github.com/abema/go-mp4"
+	"github.com/dtrejod/goexif/internal/exifdata"
+)
+
+const (
+	// scanChunkSize is the number of bytes read at a time when scanning media for an XMP packet
+	scanChunkSize = 64 * 1024
+	// maxPacketSize is the upper bound on the size of an XMP packet
+	maxPacketSize = 4 * 1024 * 1024
+	// maxScanLength bounds how far into media without ISOBMFF boxes an embedded XMP packet is looked for. Images store
+	// their packet near the start, so large media such as videos is not scanned in full.
+	maxScanLength = 16 * 1024 * 1024
+)
+
+var (
+	// packetStart and packetEnd mark the XMP metadata in a packet. Packets are
+	// designed to be found by scanning, which works for JPEG APP1 segments,
+	// PNG iTXt chunks and TIFF tags alike.
+	// Ref: https://github.com/adobe/XMP-Toolkit-SDK/blob/main/docs/XMPSpecificationPart3.pdf
+	packetStart = []byte("<x:xmpmeta")
+	packetEnd   = []byte("</x:xmpmeta>")
+
+	// xmpUUID is the uuid box type that contains XMP metadata in MP4 media
+	xmpUUID = [16]byte{0xBE, 0x7A, 0xCF, 0xCB, 0x97, 0xA9, 0x42, 0xE8, 0x9C, 0x71, 0x99, 0x94, 0x91, 0xE3, 0xAF, 0xAC}
+	// xmpBoxPaths are the BoxPaths that may contain XMP metadata in MP4 and QuickTime media
+	xmpBoxPaths = []mp4.BoxPath{
+		{mp4.StrToBoxType("uuid")},
+		{mp4.BoxTypeMoov(), mp4.BoxTypeUdta(), mp4.StrToBoxType("XMP_")},
+	}
+
+	// dateProperties are the XMP properties containing the date the media was
+	// taken in order of preference
+	// Ref: https://exiftool.org/TagNames/XMP.html
+	dateProperties = []xml.Name{
+		{Space: "http://ns.adobe.com/exif/1.0/", Local: "DateTimeOriginal"},
+		{Space: "http://ns.adobe.com/photoshop/1.0/", Local: "DateCreated"},
+		{Space: "http://ns.adobe.com/xap/1.0/", Local: "CreateDate"},
+	}
+
+	// xmpDateLayouts are the layouts of the XMP date type with and without a timezone
+	// Ref: https://developer.adobe.com/xmp/docs/XMPNamespaces/XMPDataTypes/#date
+	xmpDateLayouts = []struct {
+		layout  string
+		hasZone bool
+	}{
+		{layout: "2006-01-02T15:04:05.999999999Z07:00", hasZone: true},
+		{layout: "2006-01-02T15:04Z07:00", hasZone: true},
+		{layout: "2006-01-02T15:04:05.999999999"},
+		{layout: "2006-01-02T15:04"},
+		{layout: "2006-01-02"},
+	}
+
+	errNoPacket = errors.New("could not find XMP packet")
+)
+
+// GetTime returns the XMP metadata Datetime for media referenced in the provided path. A sidecar file (IMG_1234.xmp or
+// IMG_1234.jpg.xmp) is preferred over a packet embedded in the media itself. Dates without a timezone are returned in
+// exifdata.UnknownLocation.
+func GetTime(path string) (time.Time, error) {
+	for _, sidecar := range SidecarPaths(path) {
+		packet, err := getPacketFromFile(sidecar)
+		if err != nil {
+			continue
+		}
+		if t, err := getTimeFromPacket(packet); err == nil {
+			return t, nil
+		}
+	}
+
+	packet, err := getEmbeddedPacket(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return getTimeFromPacket(packet)
+}
+
+// SidecarPaths returns the candidate XMP sidecar paths for the provided media path
+func SidecarPaths(path string) []string {
+	base := strings.TrimSuffix(path, filepath.Ext(path))
+	return []string{
+		base + ".xmp",
+		base + ".XMP",
+		path + ".xmp",
+		path + ".XMP",
+	}
+}
+
+func getPacketFromFile(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return findPacket(f)
+}
+
+// getEmbeddedPacket returns the XMP packet embedded in the media. ISOBMFF media
+// store XMP in dedicated boxes that may be after the media data, so they are
+// read using their box structure instead of scanning the entire file. Other
+// media is only scanned up to maxScanLength.
+func getEmbeddedPacket(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(f, header); err != nil {
+		return nil, errNoPacket
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	if bytes.Equal(header[4:8], []byte("ftyp")) {
+		return getPacketFromBoxes(f)
+	}
+	return findPacket(io.LimitReader(f, maxScanLength))
+}
+
+func getPacketFromBoxes(r io.ReadSeeker) ([]byte, error) {
+	boxes, err := mp4.ExtractBoxes(r, nil, xmpBoxPaths)
+	if err != nil {
+		return nil, err
+	}
+	for _, box := range boxes {
+		size := box.Size - box.HeaderSize
+		if size > maxPacketSize {
+			continue
+		}
+		if _, err := box.SeekToPayload(r); err != nil {
+			return nil, err
+		}
+		data := make([]byte, size)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		if box.Type == mp4.StrToBoxType("uuid") {
+			if len(data) < len(xmpUUID) || !bytes.Equal(data[:len(xmpUUID)], xmpUUID[:]) {
+				continue
+			}
+			data = data[len(xmpUUID):]
+		}
+		if packet, err := findPacket(bytes.NewReader(data)); err == nil {
+			return packet, nil
+		}
+	}
+	return nil, errNoPacket
+}
+
+// findPacket scans the reader for an XMP packet and returns it
+func findPacket(r io.Reader) ([]byte, error) {
+	br := bufio.NewReaderSize(r, scanChunkSize)
+	buf := make([]byte, scanChunkSize)
+
+	var window []byte
+	start := -1
+	for {
+		n, err := br.Read(buf)
+		window = append(window, buf[:n]...)
+
+		if start < 0 {
+			if i := bytes.Index(window, packetStart); i >= 0 {
+				window = window[i:]
+				start = 0
+			} else if len(window) > len(packetStart) {
+				// keep enough bytes to match a start marker split across reads
+				window = append([]byte(nil), window[len(window)-len(packetStart):]...)
+			}
+		}
+		if start >= 0 {
+			if i := bytes.Index(window, packetEnd); i >= 0 {
+				return window[:i+len(packetEnd)], nil
+			}
+			if len(window) > maxPacketSize {
+				return nil, errors.New("XMP packet too large")
+			}
+		}
+
+		if err == io.EOF {
+			return nil, errNoPacket
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// getTimeFromPacket returns the preferred date property from the XMP packet.
+// Properties may be serialized as either attributes or elements.
+func getTimeFromPacket(packet []byte) (time.Time, error) {
+	values := make(map[xml.Name]string)
+
+	decoder := xml.NewDecoder(bytes.NewReader(packet))
+	var current *xml.Name
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			for _, attr := range t.Attr {
+				if isDateProperty(attr.Name) {
+					values[attr.Name] = attr.Value
+				}
+			}
+			current = nil
+			if isDateProperty(t.Name) {
+				name := t.Name
+				current = &name
+			}
+		case xml.CharData:
+			if current != nil {
+				values[*current] += string(t)
+			}
+		case xml.EndElement:
+			current = nil
+		}
+	}
+
+	for _, name := range dateProperties {
+		value, ok := values[name]
+		if !ok {
+			continue
+		}
+		if t, err := parseDate(value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.New("could not find known XMP date properties")
+}
+
+func isDateProperty(name xml.Name) bool {
+	for _, n := range dateProperties {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func parseDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, l := range xmpDateLayouts {
+		if l.hasZone {
+			if t, err := time.Parse(l.layout, value); err == nil {
+				return t, nil
+			}
+			continue
+		}
+		if t, err := time.ParseInLocation(l.layout, value, exifdata.UnknownLocation); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.New("unknown XMP date format")
+}
@@ -0,0 +1,105 @@
+package xmpdata
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dtrejod/goexif/internal/exifdata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	attributePacket = `<?xpacket begin="" id="W5M0MpCehiHzreSzNTczkc9d"?>
+<x:xmpmeta xmlns:x="adobe:ns:meta/">
+ <rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+  <rdf:Description rdf:about=""
+    xmlns:xmp="http://ns.adobe.com/xap/1.0/"
+    xmlns:exif="http://ns.adobe.com/exif/1.0/"
+    xmp:CreateDate="2001-01-01T00:00:00"
+    exif:DateTimeOriginal="2020-06-26T16:19:26.123-07:00"/>
+ </rdf:RDF>
+</x:xmpmeta>
+<?xpacket end="w"?>`
+
+	elementPacket = `<x:xmpmeta xmlns:x="adobe:ns:meta/">
+ <rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+  <rdf:Description rdf:about="" xmlns:photoshop="http://ns.adobe.com/photoshop/1.0/">
+   <photoshop:DateCreated>2020-06-26T16:19:26</photoshop:DateCreated>
+  </rdf:Description>
+ </rdf:RDF>
+</x:xmpmeta>`
+)
+
+func writeFile(t *testing.T, path string, data []byte) string {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, data, 0644))
+	return path
+}
+
+func TestGetTime(t *testing.T) {
+	withZone := time.Date(2020, 6, 26, 23, 19, 26, int(123*time.Millisecond), time.UTC)
+	withoutZone := time.Date(2020, 6, 26, 16, 19, 26, 0, exifdata.UnknownLocation)
+
+	t.Run("embedded in jpeg", func(t *testing.T) {
+		app1 := append([]byte("\xff\xd8\xff\xe1\x00\x00http://ns.adobe.com/xap/1.0/\x00"), attributePacket...)
+		path := writeFile(t, filepath.Join(t.TempDir(), "IMG_1234.jpg"), append(app1, 0xff, 0xd9))
+
+		actual, err := GetTime(path)
+		require.NoError(t, err)
+		assert.True(t, withZone.Equal(actual))
+	})
+
+	t.Run("embedded in mp4 uuid box", func(t *testing.T) {
+		payload := append(xmpUUID[:], elementPacket...)
+		uuid := append(binary.BigEndian.AppendUint32(nil, uint32(8+len(payload))), "uuid"...)
+		ftyp := append(binary.BigEndian.AppendUint32(nil, 16), "ftypisom\x00\x00\x00\x00"...)
+		mdat := append(binary.BigEndian.AppendUint32(nil, uint32(8+len(packetStart))), "mdat"...)
+		// the media data contains a false start marker that must not be matched
+		mdat = append(mdat, packetStart...)
+		data := append(append(append(ftyp, mdat...), uuid...), payload...)
+		path := writeFile(t, filepath.Join(t.TempDir(), "VID_1234.mp4"), data)
+
+		actual, err := GetTime(path)
+		require.NoError(t, err)
+		assert.Equal(t, withoutZone, actual)
+	})
+
+	t.Run("sidecar preferred over embedded", func(t *testing.T) {
+		dir := t.TempDir()
+		path := writeFile(t, filepath.Join(dir, "IMG_1234.jpg"), []byte(attributePacket))
+		writeFile(t, filepath.Join(dir, "IMG_1234.xmp"), []byte(elementPacket))
+
+		actual, err := GetTime(path)
+		require.NoError(t, err)
+		assert.Equal(t, withoutZone, actual)
+	})
+
+	t.Run("sidecar with media extension", func(t *testing.T) {
+		dir := t.TempDir()
+		path := writeFile(t, filepath.Join(dir, "IMG_1234.NEF"), []byte("raw"))
+		writeFile(t, filepath.Join(dir, "IMG_1234.NEF.xmp"), []byte(attributePacket))
+
+		actual, err := GetTime(path)
+		require.NoError(t, err)
+		assert.True(t, withZone.Equal(actual))
+	})
+
+	t.Run("beyond scan length", func(t *testing.T) {
+		data := append(make([]byte, maxScanLength), attributePacket...)
+		path := writeFile(t, filepath.Join(t.TempDir(), "VID_1234.avi"), data)
+
+		_, err := GetTime(path)
+		assert.Error(t, err)
+	})
+
+	t.Run("without xmp", func(t *testing.T) {
+		path := writeFile(t, filepath.Join(t.TempDir(), "IMG_1234.jpg"), []byte("\xff\xd8\xff\xd9"))
+
+		_, err := GetTime(path)
+		assert.Error(t, err)
+	})
+}